@@ -0,0 +1,47 @@
+package config
+
+import "time"
+
+// AuthConfig содержит настройки сценариев восстановления доступа и подтверждения email
+type AuthConfig struct {
+	AppBaseURL               string
+	PasswordResetTTL         time.Duration
+	EmailVerificationTTL     time.Duration
+	VerificationResendPeriod time.Duration
+	LoginProtection          LoginProtectionConfig
+}
+
+// LoginProtectionConfig — параметры защиты входа от перебора паролей
+type LoginProtectionConfig struct {
+	// Максимум неудачных попыток для аккаунта и для IP до временной блокировки
+	AccountMaxAttempts int
+	IPMaxAttempts      int
+	// После DelayAfterAttempts неудач каждая следующая попытка возможна только после задержки,
+	// которая удваивается начиная с BaseDelay и не превышает MaxDelay
+	DelayAfterAttempts int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	// Неудачи старше FailureWindow не учитываются
+	FailureWindow   time.Duration
+	LockoutDuration time.Duration
+	UnlockTokenTTL  time.Duration
+}
+
+func LoadAuth() AuthConfig {
+	return AuthConfig{
+		AppBaseURL:               getEnv("APP_BASE_URL", "http://localhost:8080"),
+		PasswordResetTTL:         getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		EmailVerificationTTL:     getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		VerificationResendPeriod: getEnvDuration("EMAIL_VERIFICATION_RESEND_PERIOD", time.Minute),
+		LoginProtection: LoginProtectionConfig{
+			AccountMaxAttempts: getEnvInt("LOGIN_ACCOUNT_MAX_ATTEMPTS", 5),
+			IPMaxAttempts:      getEnvInt("LOGIN_IP_MAX_ATTEMPTS", 20),
+			DelayAfterAttempts: getEnvInt("LOGIN_DELAY_AFTER_ATTEMPTS", 3),
+			BaseDelay:          getEnvDuration("LOGIN_BASE_DELAY", time.Second),
+			MaxDelay:           getEnvDuration("LOGIN_MAX_DELAY", 30*time.Second),
+			FailureWindow:      getEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			LockoutDuration:    getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			UnlockTokenTTL:     getEnvDuration("LOGIN_UNLOCK_TOKEN_TTL", time.Hour),
+		},
+	}
+}
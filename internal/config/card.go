@@ -0,0 +1,46 @@
+package config
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// CardConfig — ограничения операций по картам
+type CardConfig struct {
+	// Максимальная сумма одной оплаты, 0 — без ограничения
+	MaxPaymentAmount decimal.Decimal
+	// Срок, в течение которого резерв по двухфазной оплате можно списать
+	HoldTTL time.Duration
+	// Код карточного продукта, по которому выпускаются карты, если продукт не указан
+	DefaultProduct string
+	// Максимальная сумма одного снятия наличных в банкомате
+	ATMMaxWithdrawal decimal.Decimal
+	// Лимит снятия наличных по карте за календарный день UTC
+	ATMDailyLimit decimal.Decimal
+	// Комиссия за снятие наличных в процентах от суммы, но не меньше ATMMinFee
+	ATMFeePercent decimal.Decimal
+	ATMMinFee     decimal.Decimal
+	// Аккаунт банка, на который зачисляются комиссии за снятие; 0 — комиссия не взимается
+	ATMFeeAccountID uint
+	// Оплаты картой на сумму больше порога подтверждаются одноразовым кодом; 0 — подтверждение не требуется
+	OTPThreshold decimal.Decimal
+	// Срок действия одноразового кода и число попыток его ввода
+	OTPTTL         time.Duration
+	OTPMaxAttempts int
+}
+
+func LoadCard() CardConfig {
+	return CardConfig{
+		MaxPaymentAmount: getEnvDecimal("CARD_MAX_PAYMENT_AMOUNT", decimal.NewFromInt(100000)),
+		HoldTTL:          getEnvDuration("CARD_HOLD_TTL", 7*24*time.Hour),
+		DefaultProduct:   getEnv("CARD_DEFAULT_PRODUCT", "mir_debit"),
+		ATMMaxWithdrawal: getEnvDecimal("CARD_ATM_MAX_WITHDRAWAL", decimal.NewFromInt(50000)),
+		ATMDailyLimit:    getEnvDecimal("CARD_ATM_DAILY_LIMIT", decimal.NewFromInt(100000)),
+		ATMFeePercent:    getEnvDecimal("CARD_ATM_FEE_PERCENT", decimal.NewFromInt(1)),
+		ATMMinFee:        getEnvDecimal("CARD_ATM_MIN_FEE", decimal.NewFromInt(100)),
+		ATMFeeAccountID:  uint(getEnvInt("CARD_ATM_FEE_ACCOUNT_ID", 0)),
+		OTPThreshold:     getEnvDecimal("CARD_OTP_THRESHOLD", decimal.NewFromInt(10000)),
+		OTPTTL:           getEnvDuration("CARD_OTP_TTL", 5*time.Minute),
+		OTPMaxAttempts:   getEnvInt("CARD_OTP_MAX_ATTEMPTS", 3),
+	}
+}
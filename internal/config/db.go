@@ -1,7 +1,10 @@
 package config
 
 import (
+	"github.com/shopspring/decimal"
 	"os"
+	"strconv"
+	"time"
 )
 
 type DBConfig struct {
@@ -30,3 +33,32 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration читает длительность в формате time.ParseDuration (например, 15m, 1h)
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return duration
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDecimal(key string, defaultValue decimal.Decimal) decimal.Decimal {
+	value, err := decimal.NewFromString(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -1,16 +1,65 @@
 package config
 
-import "github.com/sirupsen/logrus"
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
 
 type CryptoConfig struct {
-	PGPKey  string
+	PGPKey string
+	// HMACKey — ключ pgcrypto, которым зашифрованы карты до перехода на набор ключей
 	HMACKey string
+	// CardIndexKey — ключ blind index номеров карт. Если не задан, используется HMACKey
+	CardIndexKey string
+	// CardKeys — ключи шифрования данных карт: идентификатор → 32 байта
+	CardKeys map[string][]byte
+	// CardActiveKeyID — ключ, которым шифруются новые и перешифровываемые карты
+	CardActiveKeyID string
 }
 
-func LoadCrypto() CryptoConfig {
+func LoadCrypto() (CryptoConfig, error) {
 	logrus.Info("Загружаем конфиг для шифрования")
-	return CryptoConfig{
-		PGPKey:  getEnv("PGP_KEY", "PGP_KEY"),
-		HMACKey: getEnv("HMAC_KEY", "HMAC_KEY"),
+	cardKeys, err := parseKeys(getEnv("CARD_ENCRYPTION_KEYS", ""))
+	if err != nil {
+		return CryptoConfig{}, err
 	}
+	cfg := CryptoConfig{
+		PGPKey:          getEnv("PGP_KEY", "PGP_KEY"),
+		HMACKey:         getEnv("HMAC_KEY", "HMAC_KEY"),
+		CardIndexKey:    getEnv("CARD_INDEX_KEY", ""),
+		CardKeys:        cardKeys,
+		CardActiveKeyID: getEnv("CARD_ENCRYPTION_ACTIVE_KEY", ""),
+	}
+	if cfg.CardIndexKey == "" {
+		logrus.Warn("CARD_INDEX_KEY не задан, индекс номеров карт строится по HMAC_KEY")
+		cfg.CardIndexKey = cfg.HMACKey
+	}
+	return cfg, nil
+}
+
+// parseKeys разбирает список вида "id1:base64key1,id2:base64key2". Пустые записи пропускаются,
+// а некорректная запись — ошибка: иначе данные, зашифрованные пропущенным ключом, нельзя будет расшифровать.
+func parseKeys(value string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, found := strings.Cut(entry, ":")
+		if !found || id == "" {
+			return nil, fmt.Errorf("card encryption key entry %q must look like id:base64key", entry)
+		}
+		if _, exists := keys[id]; exists {
+			return nil, fmt.Errorf("card encryption key %s is listed twice", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("card encryption key %s is not valid base64: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return keys, nil
 }
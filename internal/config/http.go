@@ -0,0 +1,20 @@
+package config
+
+import "strings"
+
+// HTTPConfig — настройки HTTP-сервера
+type HTTPConfig struct {
+	// TrustedProxies — адреса или CIDR прокси, которым разрешено передавать IP клиента в X-Forwarded-For.
+	// По умолчанию список пуст: IP клиента берётся из адреса соединения, заголовок игнорируется.
+	TrustedProxies []string
+}
+
+func LoadHTTP() HTTPConfig {
+	var proxies []string
+	for _, proxy := range strings.Split(getEnv("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return HTTPConfig{TrustedProxies: proxies}
+}
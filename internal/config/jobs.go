@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+// JobsConfig — интервалы фоновых задач, 0 отключает задачу
+type JobsConfig struct {
+	CardExpiryInterval    time.Duration
+	HoldExpiryInterval    time.Duration
+	StandingOrderInterval time.Duration
+}
+
+func LoadJobs() JobsConfig {
+	return JobsConfig{
+		CardExpiryInterval:    getEnvDuration("JOB_CARD_EXPIRY_INTERVAL", time.Hour),
+		HoldExpiryInterval:    getEnvDuration("JOB_HOLD_EXPIRY_INTERVAL", 5*time.Minute),
+		StandingOrderInterval: getEnvDuration("JOB_STANDING_ORDER_INTERVAL", time.Minute),
+	}
+}
@@ -1,24 +1,34 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"time"
 )
 
 // JWTConfig содержит настройки для JWT-токенов
 type JWTConfig struct {
-	Secret    string
-	ExpiresIn time.Duration
+	Secret string
+	// LinkSecret подписывает токены ссылок из писем. Он отличается от Secret, чтобы ссылку нельзя было
+	// предъявить как токен доступа
+	LinkSecret string
+	ExpiresIn  time.Duration
 }
 
-func LoadJWT() JWTConfig {
+// LoadJWT читает секреты подписи. Значений по умолчанию нет: без них сервис не запускается.
+func LoadJWT() (JWTConfig, error) {
 	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "zrvbJBByrUEDl4994VA4vooeVCimuVlIJjWxJ2ZZrnS7aCIYEOar6ExAH3dDhEFT"
+	linkSecret := os.Getenv("JWT_LINK_SECRET")
+	if secret == "" || linkSecret == "" {
+		return JWTConfig{}, errors.New("JWT_SECRET and JWT_LINK_SECRET must be set")
+	}
+	if secret == linkSecret {
+		return JWTConfig{}, errors.New("JWT_LINK_SECRET must differ from JWT_SECRET")
 	}
 
 	return JWTConfig{
-		Secret:    secret,
-		ExpiresIn: 24 * time.Hour,
-	}
+		Secret:     secret,
+		LinkSecret: linkSecret,
+		ExpiresIn:  24 * time.Hour,
+	}, nil
 }
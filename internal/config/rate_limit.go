@@ -0,0 +1,37 @@
+package config
+
+import (
+	"BankSystem/internal/ratelimit"
+	"time"
+)
+
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+)
+
+// RateLimitConfig — лимиты частоты запросов для групп маршрутов.
+// Store: memory — для одного экземпляра, postgres — общий лимит для нескольких экземпляров.
+type RateLimitConfig struct {
+	Store    string
+	Login    ratelimit.Limit
+	Auth     ratelimit.Limit
+	Payments ratelimit.Limit
+}
+
+func LoadRateLimit() RateLimitConfig {
+	return RateLimitConfig{
+		Store:    getEnv("RATE_LIMIT_STORE", RateLimitStoreMemory),
+		Login:    loadLimit("RATE_LIMIT_LOGIN", 5, time.Minute),
+		Auth:     loadLimit("RATE_LIMIT_AUTH", 20, time.Minute),
+		Payments: loadLimit("RATE_LIMIT_PAYMENTS", 30, time.Minute),
+	}
+}
+
+// loadLimit читает пару переменных <prefix>_REQUESTS и <prefix>_PERIOD
+func loadLimit(prefix string, requests int, period time.Duration) ratelimit.Limit {
+	return ratelimit.Limit{
+		Requests: getEnvInt(prefix+"_REQUESTS", requests),
+		Period:   getEnvDuration(prefix+"_PERIOD", period),
+	}
+}
@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+// StandingOrderConfig — повтор регулярных переводов, не выполненных из-за нехватки средств
+type StandingOrderConfig struct {
+	// Интервал между повторами для поручений с политикой retry
+	RetryInterval time.Duration
+	// Число повторов, после которого запуск пропускается до следующего по расписанию
+	MaxRetries int
+}
+
+func LoadStandingOrder() StandingOrderConfig {
+	return StandingOrderConfig{
+		RetryInterval: getEnvDuration("STANDING_ORDER_RETRY_INTERVAL", time.Hour),
+		MaxRetries:    getEnvInt("STANDING_ORDER_MAX_RETRIES", 3),
+	}
+}
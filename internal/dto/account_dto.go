@@ -10,8 +10,22 @@ type WithdrawRequest struct {
 	Amount float64 `json:"amount" binding:"required,gt=0"`
 }
 
+// TransferRequest — перевод на аккаунт по ID или пользователю по username или email (To).
+// Перевод по To зачисляется на аккаунт получателя по умолчанию.
 type TransferRequest struct {
 	FromAccountID uint    `json:"from_account_id" binding:"required"`
-	ToAccountID   uint    `json:"to_account_id" binding:"required"`
+	ToAccountID   uint    `json:"to_account_id" binding:"required_without=To,excluded_with=To"`
+	To            string  `json:"to" binding:"max=255"`
 	Amount        float64 `json:"amount" binding:"required,gt=0"`
 }
+
+type DefaultAccountRequest struct {
+	AccountID uint `json:"account_id" binding:"required,gt=0"`
+}
+
+// RecipientPreview — получатель перевода по username или email. Имя маскируется, аккаунт не раскрывается.
+type RecipientPreview struct {
+	To       string `json:"to"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+}
@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"BankSystem/internal/models"
+	"encoding/json"
+	"time"
+)
+
+type AdminUserResponse struct {
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type AdminAccountResponse struct {
+	Account *models.Account   `json:"account"`
+	Owner   AdminUserResponse `json:"owner"`
+}
+
+type CloseAccountRequest struct {
+	TransferToAccountID *uint `json:"transfer_to_account_id" binding:"omitempty,gt=0"`
+}
+
+type AuditLogResponse struct {
+	ID         uint            `json:"id"`
+	ActorID    *uint           `json:"actor_id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	RequestID  string          `json:"request_id"`
+	Details    json.RawMessage `json:"details" swaggertype:"object"`
+	Before     json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	Hash       *string         `json:"hash"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func NewAdminUserResponse(user *models.User) AdminUserResponse {
+	return AdminUserResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.IsEmailVerified(),
+		CreatedAt:     user.CreatedAt,
+	}
+}
+
+func NewAuditLogResponse(entry *models.AuditLog) AuditLogResponse {
+	response := AuditLogResponse{
+		ID:         entry.ID,
+		ActorID:    entry.ActorID,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		IP:         entry.IP,
+		UserAgent:  entry.UserAgent,
+		RequestID:  entry.RequestID,
+		Details:    json.RawMessage(entry.Details),
+		Hash:       entry.Hash,
+		CreatedAt:  entry.CreatedAt,
+	}
+	if entry.Details == "" {
+		response.Details = json.RawMessage("{}")
+	}
+	if entry.BeforeValue != nil {
+		response.Before = json.RawMessage(*entry.BeforeValue)
+	}
+	if entry.AfterValue != nil {
+		response.After = json.RawMessage(*entry.AfterValue)
+	}
+	return response
+}
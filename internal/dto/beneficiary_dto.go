@@ -0,0 +1,27 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// CreateBeneficiaryRequest — получатель задаётся либо аккаунтом банка, либо номером карты
+type CreateBeneficiaryRequest struct {
+	Nickname   string `json:"nickname" binding:"required,max=100"`
+	AccountID  uint   `json:"account_id" binding:"required_without=CardNumber,excluded_with=CardNumber"`
+	CardNumber string `json:"card_number" binding:"omitempty,pan"`
+}
+
+// CreateTemplateRequest — шаблон перевода сохранённому получателю
+type CreateTemplateRequest struct {
+	Name          string  `json:"name" binding:"required,max=100"`
+	BeneficiaryID uint    `json:"beneficiary_id" binding:"required,gt=0"`
+	FromAccountID uint    `json:"from_account_id" binding:"required,gt=0"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Comment       string  `json:"comment" binding:"max=255"`
+}
+
+type TemplateExecutionResponse struct {
+	TemplateID    uint            `json:"template_id"`
+	Beneficiary   string          `json:"beneficiary"`
+	FromAccountID uint            `json:"from_account_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	Balance       decimal.Decimal `json:"balance"`
+}
@@ -5,35 +5,155 @@ import (
 	"time"
 )
 
+// CreateCardRequest — выпуск карты. Одноразовость, лимит на весь срок и срок действия задаются только для виртуальных карт.
 type CreateCardRequest struct {
-	AccountID uint `json:"account_id" binding:"required,gt=0"`
+	AccountID   uint       `json:"account_id" binding:"required,gt=0"`
+	Product     string     `json:"product"`
+	Kind        string     `json:"kind" binding:"omitempty,oneof=physical virtual"`
+	SingleUse   bool       `json:"single_use"`
+	SpendingCap *float64   `json:"spending_cap" binding:"omitempty,gt=0"`
+	ExpiresAt   *time.Time `json:"expires_at"`
 }
 
 type NewCardResponse struct {
+	ID     uint   `json:"id"`
+	Kind   string `json:"kind"`
 	Number string `json:"number"`
 	CVV    string `json:"cvv"`
 	Expiry string `json:"expiry"`
 }
 
 type CardResponse struct {
-	ID        uint            `json:"id" gorm:"primaryKey"`
-	AccountID uint            `json:"account_id" gorm:"not null"`
-	Balance   decimal.Decimal `json:"balance" gorm:"column:balance;type:numeric(12,2);default:0.00"`
-	Number    string          `json:"number" gorm:"column:card_number;type:text;unique"`
-	Currency  string          `json:"currency" gorm:"column:currency;type:text;unique"`
-	CVV       string          `json:"cvv" gorm:"type:text"`
-	ExpiredAt time.Time       `json:"expired_at" gorm:"column:expired_at"`
-	CreatedAt time.Time       `json:"created_at" gorm:"column:created_at"`
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	AccountID     uint            `json:"account_id" gorm:"not null"`
+	Balance       decimal.Decimal `json:"balance" gorm:"column:balance;type:numeric(12,2);default:0.00"`
+	Number        string          `json:"number" gorm:"column:card_number;type:text;unique"`
+	KeyID         *string         `json:"-" gorm:"column:card_key_id"`
+	Currency      string          `json:"currency" gorm:"column:currency;type:text;unique"`
+	CVV           string          `json:"cvv" gorm:"type:text"`
+	Status        string          `json:"status" gorm:"column:status"`
+	Kind          string          `json:"kind" gorm:"column:kind"`
+	PaymentSystem string          `json:"payment_system,omitempty" gorm:"column:payment_system"`
+	SingleUse     bool            `json:"single_use" gorm:"column:single_use"`
+	// SpendingCap — лимит виртуальной карты на весь срок действия
+	SpendingCap *decimal.Decimal `json:"spending_cap,omitempty" gorm:"column:spending_cap"`
+	// SpentThisMonth — оплаты и резервы по карте с начала календарного месяца UTC
+	SpentThisMonth decimal.Decimal `json:"spent_this_month" gorm:"-"`
+	ExpiredAt      time.Time       `json:"expired_at" gorm:"column:expired_at"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"column:created_at"`
 }
 
 type CardPaymentRequest struct {
-	CardNumber string    `json:"card_number" binding:"required"`
+	MerchantID uint      `json:"merchant_id" binding:"required,gt=0"`
+	CardNumber string    `json:"card_number" binding:"required,pan"`
 	Cvv        string    `json:"cvv" binding:"required,len=3"`
 	Amount     float64   `json:"amount" binding:"required,gt=0"`
-	ExpiredAt  time.Time `json:"expired_at"`
+	ExpiredAt  time.Time `json:"expired_at" binding:"required"`
+	// Tokenize — выдать мерчанту токен карты для повторных оплат, если оплата списана сразу и её проводит владелец мерчанта
+	Tokenize bool `json:"tokenize"`
+}
+
+// TokenPaymentRequest — оплата, инициированная мерчантом по ранее выданному токену карты
+type TokenPaymentRequest struct {
+	MerchantID uint    `json:"merchant_id" binding:"required,gt=0"`
+	Token      string  `json:"token" binding:"required"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
 }
 
 type CardPaymentResponse struct {
 	Result  bool   `json:"result" binding:"required"`
+	Code    string `json:"code"`
 	Message string `json:"message" binding:"required,len=3"`
+	// Token — токен карты для мерчанта, возвращается один раз при оплате с tokenize
+	Token string `json:"token,omitempty"`
+	// PaymentID — оплата, ожидающая подтверждения одноразовым кодом
+	PaymentID uint `json:"payment_id,omitempty"`
+}
+
+// CardLimitsRequest — новые лимиты карты. Отсутствующее поле снимает соответствующее ограничение.
+type CardLimitsRequest struct {
+	PerTransaction  *float64 `json:"per_transaction" binding:"omitempty,gt=0"`
+	Daily           *float64 `json:"daily" binding:"omitempty,gt=0"`
+	Monthly         *float64 `json:"monthly" binding:"omitempty,gt=0"`
+	PaymentsPerHour *int     `json:"payments_per_hour" binding:"omitempty,gt=0"`
+}
+
+type CardLimitsResponse struct {
+	CardID           uint             `json:"card_id"`
+	PerTransaction   *decimal.Decimal `json:"per_transaction"`
+	Daily            *decimal.Decimal `json:"daily"`
+	Monthly          *decimal.Decimal `json:"monthly"`
+	PaymentsPerHour  *int             `json:"payments_per_hour"`
+	SpentToday       decimal.Decimal  `json:"spent_today"`
+	SpentThisMonth   decimal.Decimal  `json:"spent_this_month"`
+	PaymentsLastHour int64            `json:"payments_last_hour"`
+}
+
+// PaymentAmountRequest — сумма частичного списания или возврата. Без суммы операция выполняется на весь остаток.
+type PaymentAmountRequest struct {
+	Amount *float64 `json:"amount" binding:"omitempty,gt=0"`
+	// Tokenize — при списании выдать мерчанту токен карты для повторных оплат
+	Tokenize bool `json:"tokenize"`
+}
+
+// PaymentConfirmRequest — одноразовый код, отправленный владельцу карты
+type PaymentConfirmRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+	// Tokenize — выдать мерчанту токен карты, если подтверждённая оплата списана сразу
+	Tokenize bool `json:"tokenize"`
+}
+
+type PaymentHoldResponse struct {
+	ID             uint            `json:"id"`
+	MerchantID     *uint           `json:"merchant_id"`
+	TokenID        *uint           `json:"token_id,omitempty"`
+	Code           string          `json:"code"`
+	Status         string          `json:"status"`
+	Amount         decimal.Decimal `json:"amount"`
+	CapturedAmount decimal.Decimal `json:"captured_amount"`
+	RefundedAmount decimal.Decimal `json:"refunded_amount"`
+	Currency       string          `json:"currency"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	// Token — токен карты для мерчанта, возвращается один раз при авторизации с tokenize
+	Token string `json:"token,omitempty"`
+}
+
+type SetPINRequest struct {
+	PIN string `json:"pin" binding:"required,len=4,numeric"`
+}
+
+type ChangePINRequest struct {
+	OldPIN string `json:"old_pin" binding:"required,len=4,numeric"`
+	NewPIN string `json:"new_pin" binding:"required,len=4,numeric,nefield=OldPIN"`
+}
+
+// ATMWithdrawRequest — снятие наличных в банкомате по номеру карты и PIN
+type ATMWithdrawRequest struct {
+	CardNumber string  `json:"card_number" binding:"required,pan"`
+	PIN        string  `json:"pin" binding:"required,len=4,numeric"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// CardTransferRequest — перевод с карты текущего пользователя на карту получателя по номеру
+type CardTransferRequest struct {
+	CardNumber          string    `json:"card_number" binding:"required,pan"`
+	Cvv                 string    `json:"cvv" binding:"required,len=3"`
+	ExpiredAt           time.Time `json:"expired_at" binding:"required"`
+	RecipientCardNumber string    `json:"recipient_card_number" binding:"required,pan,nefield=CardNumber"`
+	Amount              float64   `json:"amount" binding:"required,gt=0"`
+}
+
+type CardTransferResponse struct {
+	Code          string          `json:"code"`
+	Amount        decimal.Decimal `json:"amount"`
+	Balance       decimal.Decimal `json:"balance"`
+	RecipientCard string          `json:"recipient_card"`
+}
+
+type ATMWithdrawResponse struct {
+	Code    string          `json:"code"`
+	Amount  decimal.Decimal `json:"amount"`
+	Fee     decimal.Decimal `json:"fee"`
+	Balance decimal.Decimal `json:"balance"`
 }
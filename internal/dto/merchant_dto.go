@@ -0,0 +1,6 @@
+package dto
+
+type CreateMerchantRequest struct {
+	Name                string `json:"name" binding:"required,max=255"`
+	SettlementAccountID uint   `json:"settlement_account_id" binding:"required,gt=0"`
+}
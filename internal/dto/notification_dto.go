@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type PasswordResetNotification struct {
+	To        string
+	Name      string
+	Link      string
+	ExpiresAt time.Time
+}
+
+type EmailVerificationNotification struct {
+	To        string
+	Name      string
+	Link      string
+	ExpiresAt time.Time
+}
+
+// PaymentOTPNotification — одноразовый код для подтверждения оплаты картой
+type PaymentOTPNotification struct {
+	To         string
+	Name       string
+	Code       string
+	CardMasked string
+	Amount     decimal.Decimal
+	ExpiresAt  time.Time
+}
+
+// CardTransferNotification — уведомление о переводе с карты на карту. Incoming — письмо получателю о зачислении.
+type CardTransferNotification struct {
+	To                 string
+	Name               string
+	Incoming           bool
+	CardMasked         string
+	CounterpartyMasked string
+	Amount             decimal.Decimal
+	Balance            decimal.Decimal
+	Date               time.Time
+}
+
+// StandingOrderFailedNotification — перевод по регулярному поручению не выполнен.
+// NextAttemptAt — повтор или следующий запуск, Cancelled — поручение отменено.
+type StandingOrderFailedNotification struct {
+	To            string
+	Name          string
+	OrderID       uint
+	ToAccountID   uint
+	Amount        decimal.Decimal
+	Reason        string
+	NextAttemptAt *time.Time
+	Cancelled     bool
+	Date          time.Time
+}
+
+type AccountLockedNotification struct {
+	To          string
+	Name        string
+	Link        string
+	LockedUntil time.Time
+}
@@ -6,10 +6,11 @@ import (
 )
 
 type PaymentNotification struct {
-	To        string
-	Name      string
-	CardLast4 string
-	Amount    decimal.Decimal
-	Balance   decimal.Decimal
-	Date      time.Time
+	To   string
+	Name string
+	// CardMasked — маскированный номер карты, полный номер в письма не попадает
+	CardMasked string
+	Amount     decimal.Decimal
+	Balance    decimal.Decimal
+	Date       time.Time
 }
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// CreateStandingOrderRequest — регулярный перевод. Recurrence: daily, weekly, monthly или cron-выражение из пяти полей (UTC).
+type CreateStandingOrderRequest struct {
+	FromAccountID       uint       `json:"from_account_id" binding:"required,gt=0"`
+	ToAccountID         uint       `json:"to_account_id" binding:"required,gt=0,nefield=FromAccountID"`
+	Amount              float64    `json:"amount" binding:"required,gt=0"`
+	Recurrence          string     `json:"recurrence" binding:"required,max=100"`
+	StartAt             time.Time  `json:"start_at" binding:"required"`
+	EndAt               *time.Time `json:"end_at"`
+	OnInsufficientFunds string     `json:"on_insufficient_funds" binding:"omitempty,oneof=skip retry"`
+	Description         string     `json:"description" binding:"max=255"`
+}
@@ -2,24 +2,29 @@ package handlers
 
 import (
 	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
 	"BankSystem/internal/services"
 	accountService "BankSystem/internal/services/account"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
 	"net/http"
+	"strconv"
 )
 
 type AccountHandler struct {
 	accountService *accountService.AccountService
 	userService    *services.UserService
 	authService    *services.AuthService
+	auditService   *services.AuditService
 }
 
-func NewAccountHandler(accountService *accountService.AccountService, userService *services.UserService, authService *services.AuthService) *AccountHandler {
+func NewAccountHandler(accountService *accountService.AccountService, userService *services.UserService, authService *services.AuthService, auditService *services.AuditService) *AccountHandler {
 	return &AccountHandler{
 		accountService: accountService,
 		userService:    userService,
 		authService:    authService,
+		auditService:   auditService,
 	}
 }
 
@@ -41,12 +46,20 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 	}
 
 	initialBalance := decimal.NewFromInt(0)
-	err = h.accountService.CreateAccount(user.ID, initialBalance)
+	account, err := h.accountService.CreateAccount(user.ID, initialBalance)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not create account"})
 		return
 	}
 
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionAccountCreate,
+		EntityType:   models.AuditEntityAccount,
+		EntityID:     strconv.Itoa(int(account.ID)),
+		After:        account,
+	})
+
 	c.JSON(http.StatusCreated, gin.H{"message": "Account created successfully"})
 }
 
@@ -75,12 +88,15 @@ func (h *AccountHandler) Deposit(c *gin.Context) {
 		return
 	}
 
-	newBalance, err := h.accountService.Deposit(req.Id, user.ID, decimal.NewFromFloat(req.Amount))
+	amount := decimal.NewFromFloat(req.Amount)
+	newBalance, err := h.accountService.Deposit(req.Id, user.ID, amount)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.AbortWithStatusJSON(accountErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	h.recordBalanceChange(c, models.AuditActionAccountDeposit, req.Id, newBalance.Sub(amount), newBalance, amount)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":     "Deposit successful",
 		"new_balance": newBalance.StringFixed(2),
@@ -98,6 +114,7 @@ func (h *AccountHandler) Deposit(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 402 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /account/withdraw [post]
 func (h *AccountHandler) Withdraw(c *gin.Context) {
@@ -113,16 +130,15 @@ func (h *AccountHandler) Withdraw(c *gin.Context) {
 		return
 	}
 
-	newBalance, err := h.accountService.Withdraw(req.Id, user.ID, decimal.NewFromFloat(req.Amount))
+	amount := decimal.NewFromFloat(req.Amount)
+	newBalance, err := h.accountService.Withdraw(req.Id, user.ID, amount)
 	if err != nil {
-		if err.Error() == "insufficient funds" {
-			c.AbortWithStatusJSON(http.StatusPaymentRequired, gin.H{"error": "Insufficient funds"})
-			return
-		}
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.AbortWithStatusJSON(accountErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	h.recordBalanceChange(c, models.AuditActionAccountWithdraw, req.Id, newBalance.Add(amount), newBalance, amount)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":     "Withdrawal successful",
 		"new_balance": newBalance.StringFixed(2),
@@ -138,7 +154,7 @@ func (h *AccountHandler) Withdraw(c *gin.Context) {
 // @Success 200 {array} models.Account
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /accounts [get]
+// @Router /account/all [get]
 func (h *AccountHandler) GetAllAccounts(c *gin.Context) {
 	user, err := h.authService.GetCurrentUser(c)
 	if err != nil || user == nil {
@@ -157,7 +173,8 @@ func (h *AccountHandler) GetAllAccounts(c *gin.Context) {
 
 // Transfer godoc
 // @Summary Перевод между аккаунтами
-// @Description Выполняет перевод средств между аккаунтами (своими или чужими)
+// @Description Выполняет перевод средств между аккаунтами (своими или чужими).
+// @Description Вместо to_account_id можно указать to — username или email получателя, перевод зачислится на его аккаунт по умолчанию
 // @Tags account
 // @Security BearerAuth
 // @Accept json
@@ -166,8 +183,11 @@ func (h *AccountHandler) GetAllAccounts(c *gin.Context) {
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 402 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /account/transfer [post]
+// @Router /transfer/create [post]
 func (h *AccountHandler) Transfer(c *gin.Context) {
 	var req dto.TransferRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -181,16 +201,151 @@ func (h *AccountHandler) Transfer(c *gin.Context) {
 		return
 	}
 
-	err = h.accountService.Transfer(user.ID, req.FromAccountID, req.ToAccountID, decimal.NewFromFloat(req.Amount))
-	if err != nil {
-		switch err.Error() {
-		case "insufficient funds":
-			c.AbortWithStatusJSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
-		default:
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	toAccountID := req.ToAccountID
+	if req.To != "" {
+		recipient, err := h.userService.ResolveRecipient(req.To)
+		if err != nil {
+			c.AbortWithStatusJSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+			return
 		}
+		toAccountID = recipient.AccountID
+	}
+
+	amount := decimal.NewFromFloat(req.Amount)
+	err = h.accountService.Transfer(user.ID, req.FromAccountID, toAccountID, amount)
+	if err != nil {
+		c.AbortWithStatusJSON(accountErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	details := map[string]interface{}{
+		"from_account_id": req.FromAccountID,
+		"to_account_id":   toAccountID,
+		"amount":          amount.StringFixed(2),
+	}
+	if req.To != "" {
+		details["to"] = req.To
+	}
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionTransfer,
+		EntityType:   models.AuditEntityAccount,
+		EntityID:     strconv.Itoa(int(req.FromAccountID)),
+		Details:      details,
+	})
+
 	c.JSON(http.StatusOK, gin.H{"message": "Transfer successful"})
 }
+
+// PreviewTransfer godoc
+// @Summary Получатель перевода по username или email
+// @Description Показывает маскированное имя получателя и валюту его аккаунта по умолчанию, чтобы проверить получателя до перевода
+// @Tags account
+// @Security BearerAuth
+// @Produce json
+// @Param to query string true "Username или email получателя"
+// @Success 200 {object} dto.RecipientPreview
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /transfer/preview [get]
+func (h *AccountHandler) PreviewTransfer(c *gin.Context) {
+	to := c.Query("to")
+	if to == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "to is required"})
+		return
+	}
+
+	recipient, err := h.userService.ResolveRecipient(to)
+	if err != nil {
+		c.AbortWithStatusJSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RecipientPreview{
+		To:       to,
+		Name:     recipient.MaskedName,
+		Currency: recipient.Currency,
+	})
+}
+
+// SetDefaultAccount godoc
+// @Summary Аккаунт для входящих переводов
+// @Description Назначает аккаунт текущего пользователя, на который зачисляются переводы по его username или email
+// @Tags account
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.DefaultAccountRequest true "ID аккаунта"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /account/default [put]
+func (h *AccountHandler) SetDefaultAccount(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req dto.DefaultAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.SetDefaultAccount(user.ID, req.AccountID); err != nil {
+		c.AbortWithStatusJSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionAccountSetDefault,
+		EntityType:   models.AuditEntityAccount,
+		EntityID:     strconv.Itoa(int(req.AccountID)),
+		Before:       map[string]interface{}{"default_account_id": user.DefaultAccountID},
+		After:        map[string]interface{}{"default_account_id": req.AccountID},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Default account updated"})
+}
+
+func (h *AccountHandler) recordBalanceChange(c *gin.Context, action string, accountID uint, before decimal.Decimal, after decimal.Decimal, amount decimal.Decimal) {
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       action,
+		EntityType:   models.AuditEntityAccount,
+		EntityID:     strconv.Itoa(int(accountID)),
+		Before:       map[string]string{"balance": before.StringFixed(2)},
+		After:        map[string]string{"balance": after.StringFixed(2)},
+		Details:      map[string]interface{}{"amount": amount.StringFixed(2)},
+	})
+}
+
+// accountErrorStatus — HTTP-статус для ошибок операций со счётом
+func accountErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, accountService.ErrInsufficientFunds):
+		return http.StatusPaymentRequired
+	case accountService.IsStatusError(err):
+		return http.StatusForbidden
+	case errors.Is(err, accountService.ErrAccountNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, accountService.ErrSameAccount),
+		errors.Is(err, services.ErrInvalidDefaultAccount):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrRecipientNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrRecipientNoAccount):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, accountService.ErrInvalidStatusTransition),
+		errors.Is(err, accountService.ErrNonZeroBalance),
+		errors.Is(err, accountService.ErrActiveHolds),
+		errors.Is(err, accountService.ErrInvalidClosureTarget):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
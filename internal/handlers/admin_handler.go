@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/repositories"
+	"BankSystem/internal/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AdminHandler struct {
+	adminService *services.AdminService
+}
+
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// ListUsers godoc
+// @Summary Список пользователей
+// @Description Возвращает пользователей системы постранично. Доступно операторам и администраторам
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Количество записей (по умолчанию 50, максимум 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} dto.AdminUserResponse
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	limit, offset := parsePagination(c)
+
+	users, err := h.adminService.ListUsers(auditContext(c), limit, offset)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not load users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetAccount godoc
+// @Summary Просмотр любого аккаунта
+// @Description Возвращает аккаунт и данные его владельца. Доступно операторам и администраторам
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID аккаунта"
+// @Success 200 {object} dto.AdminAccountResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/accounts/{id} [get]
+func (h *AdminHandler) GetAccount(c *gin.Context) {
+	accountID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := h.adminService.GetAccount(auditContext(c), accountID)
+	if err != nil {
+		if errors.Is(err, services.ErrAccountNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not load account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// GetAccountTransactions godoc
+// @Summary Операции любого аккаунта
+// @Description Возвращает операции аккаунта постранично, новые первыми. Доступно операторам и администраторам
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID аккаунта"
+// @Param limit query int false "Количество записей (по умолчанию 50, максимум 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.Transaction
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/accounts/{id}/transactions [get]
+func (h *AdminHandler) GetAccountTransactions(c *gin.Context) {
+	accountID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset := parsePagination(c)
+	transactions, err := h.adminService.GetAccountTransactions(auditContext(c), accountID, limit, offset)
+	if err != nil {
+		if errors.Is(err, services.ErrAccountNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not load transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+}
+
+// FreezeAccount godoc
+// @Summary Заморозка аккаунта
+// @Description Запрещает списания с аккаунта, зачисления остаются доступны. Доступно операторам и администраторам
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID аккаунта"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/accounts/{id}/freeze [post]
+func (h *AdminHandler) FreezeAccount(c *gin.Context) {
+	h.changeAccountStatus(c, models.AccountStatusFrozen)
+}
+
+// BlockAccount godoc
+// @Summary Блокировка аккаунта
+// @Description Запрещает любые операции по аккаунту. Доступно операторам и администраторам
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID аккаунта"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/accounts/{id}/block [post]
+func (h *AdminHandler) BlockAccount(c *gin.Context) {
+	h.changeAccountStatus(c, models.AccountStatusBlocked)
+}
+
+// ActivateAccount godoc
+// @Summary Разблокировка аккаунта
+// @Description Возвращает замороженный или заблокированный аккаунт в активное состояние. Доступно операторам и администраторам
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID аккаунта"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/accounts/{id}/activate [post]
+func (h *AdminHandler) ActivateAccount(c *gin.Context) {
+	h.changeAccountStatus(c, models.AccountStatusActive)
+}
+
+// CloseAccount godoc
+// @Summary Закрытие аккаунта
+// @Description Закрывает аккаунт. Если баланс не нулевой, остаток переводится на указанный аккаунт. Доступно операторам и администраторам
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID аккаунта"
+// @Param request body dto.CloseAccountRequest false "Аккаунт для перевода остатка"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/accounts/{id}/close [post]
+func (h *AdminHandler) CloseAccount(c *gin.Context) {
+	accountID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.CloseAccountRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	transferred, err := h.adminService.CloseAccount(auditContext(c), accountID, req.TransferToAccountID)
+	if err != nil {
+		c.AbortWithStatusJSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Account closed",
+		"transferred": transferred.StringFixed(2),
+	})
+}
+
+func (h *AdminHandler) changeAccountStatus(c *gin.Context, status string) {
+	accountID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.adminService.ChangeAccountStatus(auditContext(c), accountID, status); err != nil {
+		c.AbortWithStatusJSON(accountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account status changed to " + status})
+}
+
+// ListAuditLogs godoc
+// @Summary Журнал аудита
+// @Description Возвращает записи журнала аудита, новые первыми. Доступно только администраторам
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param actor_id query int false "ID инициатора"
+// @Param action query string false "Действие, например account.deposit"
+// @Param entity_type query string false "Тип сущности: user, account, card, ip"
+// @Param entity_id query string false "ID сущности"
+// @Param from query string false "Начало периода (RFC3339)"
+// @Param to query string false "Конец периода (RFC3339)"
+// @Param limit query int false "Количество записей (по умолчанию 50, максимум 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} dto.AuditLogResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/audit [get]
+func (h *AdminHandler) ListAuditLogs(c *gin.Context) {
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset := parsePagination(c)
+	entries, err := h.adminService.FindAuditLogs(auditContext(c), filter, limit, offset)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not load audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// VerifyAuditChain godoc
+// @Summary Проверка целостности журнала аудита
+// @Description Пересчитывает цепочку хешей журнала и возвращает ID первой изменённой записи. Доступно только администраторам
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} services.AuditChainReport
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/audit/verify [get]
+func (h *AdminHandler) VerifyAuditChain(c *gin.Context) {
+	report, err := h.adminService.VerifyAuditChain(auditContext(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not verify audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func parseAuditLogFilter(c *gin.Context) (repositories.AuditLogFilter, error) {
+	filter := repositories.AuditLogFilter{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+	}
+
+	if value := c.Query("actor_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return filter, errors.New("invalid actor_id")
+		}
+		actorID := uint(id)
+		filter.ActorID = &actorID
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, errors.New("invalid from, expected RFC3339")
+		}
+		from = from.UTC()
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, errors.New("invalid to, expected RFC3339")
+		}
+		to = to.UTC()
+		filter.To = &to
+	}
+	return filter, nil
+}
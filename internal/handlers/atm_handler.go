@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/services"
+	"BankSystem/internal/utils/pan"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+)
+
+type ATMHandler struct {
+	cardService  *services.CardService
+	auditService *services.AuditService
+}
+
+func NewATMHandler(cardService *services.CardService, auditService *services.AuditService) *ATMHandler {
+	return &ATMHandler{
+		cardService:  cardService,
+		auditService: auditService,
+	}
+}
+
+// Withdraw godoc
+// @Summary Снятие наличных в банкомате
+// @Description Проверяет номер карты, срок действия, статус карты, PIN, статус аккаунта, лимиты на наличные и баланс.
+// @Description Списывает сумму и комиссию. При отказе возвращает код ответа: 05, 14, 41, 51, 54, 55, 57, 61, 62, 75, 96
+// @Tags atm
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ATMWithdrawRequest true "Номер карты, PIN и сумма"
+// @Success 200 {object} dto.ATMWithdrawResponse
+// @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /atm/withdraw [post]
+func (h *ATMHandler) Withdraw(c *gin.Context) {
+	var req dto.ATMWithdrawRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	withdrawal, err := h.cardService.WithdrawCash(req)
+	code := services.AuthorizationCode(err)
+	details := map[string]interface{}{
+		"last4":  pan.Last4(req.CardNumber),
+		"amount": decimal.NewFromFloat(req.Amount).StringFixed(2),
+		"code":   code,
+	}
+	entityID := ""
+	if err != nil {
+		details["reason"] = err.Error()
+	} else {
+		entityID = strconv.Itoa(int(withdrawal.CardID))
+		details["fee"] = withdrawal.Fee.StringFixed(2)
+	}
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionATMWithdraw,
+		EntityType:   models.AuditEntityCard,
+		EntityID:     entityID,
+		Details:      details,
+	})
+
+	if err != nil {
+		message := err.Error()
+		if code == services.AuthCodeSystemMalfunction {
+			message = "withdrawal failed"
+		}
+		c.AbortWithStatusJSON(authorizationStatus(code), gin.H{"code": code, "error": message})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ATMWithdrawResponse{
+		Code:    code,
+		Amount:  withdrawal.Amount,
+		Fee:     withdrawal.Fee,
+		Balance: withdrawal.Balance,
+	})
+}
@@ -10,36 +10,66 @@ package handlers
 
 import (
 	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
 	"BankSystem/internal/services"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 )
 
 var JwtKey = []byte(os.Getenv("JWT_SECRET"))
 
+// dummyPasswordHash — bcrypt-хеш с той же стоимостью, что у паролей пользователей, для входа с неизвестным email
+const dummyPasswordHash = "$2a$10$Fcy/1RBLiegRVS5RkGxQROz7ohRLNxVjtuD5.An/4.VRv60uTrLaW"
+
 var (
 	ErrUserNotFound   = errors.New("user not found")
 	ErrEmailExists    = errors.New("user with this email already exists")
 	ErrUsernameExists = errors.New("username is already taken")
 )
 
+// AuthClaims — claims JWT-токена доступа
+type AuthClaims struct {
+	jwt.RegisteredClaims
+	UserID uint   `json:"user_id"`
+	Role   string `json:"role"`
+	// Purpose есть только у токенов ссылок из писем, токен доступа его не содержит
+	Purpose string `json:"purpose,omitempty"`
+}
+
 type AuthHandler struct {
-	userService *services.UserService
+	userService            *services.UserService
+	passwordService        *services.PasswordService
+	verificationService    *services.VerificationService
+	loginProtectionService *services.LoginProtectionService
+	auditService           *services.AuditService
 }
 
-func NewAuthHandler(userService *services.UserService) *AuthHandler {
-	return &AuthHandler{userService: userService}
+func NewAuthHandler(
+	userService *services.UserService,
+	passwordService *services.PasswordService,
+	verificationService *services.VerificationService,
+	loginProtectionService *services.LoginProtectionService,
+	auditService *services.AuditService) *AuthHandler {
+	return &AuthHandler{
+		userService:            userService,
+		passwordService:        passwordService,
+		verificationService:    verificationService,
+		loginProtectionService: loginProtectionService,
+		auditService:           auditService,
+	}
 }
 
 // Register godoc
 // @Summary Регистрация нового пользователя
-// @Description Создает нового пользователя в системе
+// @Description Создает нового пользователя в системе и отправляет письмо для подтверждения email
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -87,16 +117,23 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	tokenString, err := h.generateJWT(user.ID, user.Email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
-		return
+	if err := h.verificationService.SendVerification(user); err != nil {
+		logrus.Error(err.Error())
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": tokenString,
+	actx := auditContext(c)
+	actx.ActorID = &user.ID
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionRegister,
+		EntityType:   models.AuditEntityUser,
+		EntityID:     strconv.Itoa(int(user.ID)),
+		After:        dto.NewAdminUserResponse(user),
 	})
 
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User registered, check your email to verify the address",
+	})
 }
 
 // Login godoc
@@ -109,6 +146,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 423 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
@@ -117,41 +156,256 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetUserByEmail(req.Email)
-	if err != nil || user == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	user, _ := h.userService.GetUserByEmail(req.Email)
+
+	attempt, err := h.loginProtectionService.Reserve(user, c.ClientIP())
+	if err != nil {
+		h.respondLoginBlocked(c, err)
 		return
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
-	if err != nil {
+	// Для несуществующего email пароль сверяется с заглушкой, чтобы время ответа не выдавало, зарегистрирован ли адрес
+	passwordHash := dummyPasswordHash
+	if user != nil {
+		passwordHash = user.Password
+	}
+	passwordErr := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password))
+
+	actx := auditContext(c)
+	if user == nil || passwordErr != nil {
+		entityID := ""
+		if user != nil {
+			entityID = strconv.Itoa(int(user.ID))
+		}
+		h.auditService.TryRecord(services.AuditEntry{
+			AuditContext: actx,
+			Action:       models.AuditActionLoginFailed,
+			EntityType:   models.AuditEntityUser,
+			EntityID:     entityID,
+			Details:      map[string]interface{}{"email": req.Email},
+		})
+
+		if err := h.loginProtectionService.RegisterFailure(user, attempt, actx); err != nil {
+			logrus.Error(err.Error())
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	tokenString, err := h.generateJWT(user.ID, user.Email)
+	if err := h.loginProtectionService.RegisterSuccess(user, c.ClientIP()); err != nil {
+		logrus.Error(err.Error())
+	}
+
+	actx.ActorID = &user.ID
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionLogin,
+		EntityType:   models.AuditEntityUser,
+		EntityID:     strconv.Itoa(int(user.ID)),
+	})
+
+	tokenString, err := h.generateJWT(user.ID, user.Email, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token": tokenString,
+		"token":          tokenString,
+		"email_verified": user.IsEmailVerified(),
 	})
 }
 
-func (h *AuthHandler) generateJWT(id uint, email string) (string, error) {
+// UnlockLogin godoc
+// @Summary Разблокировка входа
+// @Description Снимает временную блокировку входа по одноразовой ссылке из письма
+// @Tags auth
+// @Produce json
+// @Param token query string true "Токен из ссылки"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/unlock [get]
+func (h *AuthHandler) UnlockLogin(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	err := h.loginProtectionService.Unlock(token, auditContext(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidUnlockToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logrus.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not unlock login"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Login unlocked"})
+}
+
+// VerifyEmail godoc
+// @Summary Подтверждение email
+// @Description Подтверждает email пользователя по подписанной ссылке из письма
+// @Tags auth
+// @Produce json
+// @Param token query string true "Токен из ссылки"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/verify [get]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	err := h.verificationService.Verify(token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidVerificationToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logrus.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+// ResendVerification godoc
+// @Summary Повторная отправка письма подтверждения
+// @Description Повторно отправляет ссылку подтверждения email, не чаще одного раза за период ограничения
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ResendVerificationRequest true "Email пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/verify/resend [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req dto.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.verificationService.Resend(req.Email)
+	if err != nil {
+		if errors.Is(err, services.ErrVerificationThrottled) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		logrus.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the email is registered and not verified, a new link has been sent"})
+}
+
+// ForgotPassword godoc
+// @Summary Запрос на сброс пароля
+// @Description Отправляет на email одноразовую ссылку для сброса пароля. Ответ не зависит от того, существует ли пользователь
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ForgotPasswordRequest true "Email пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.passwordService.RequestReset(req.Email); err != nil {
+		logrus.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not process request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the email is registered, a reset link has been sent"})
+}
+
+// ResetPassword godoc
+// @Summary Сброс пароля
+// @Description Устанавливает новый пароль по токену из письма и завершает все ранее выданные сессии
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ResetPasswordRequest true "Токен и новый пароль"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := h.passwordService.ResetPassword(req.Token, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logrus.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not reset password"})
+		return
+	}
+
+	actx := auditContext(c)
+	actx.ActorID = &userID
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionPasswordReset,
+		EntityType:   models.AuditEntityUser,
+		EntityID:     strconv.Itoa(int(userID)),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
+
+func (h *AuthHandler) respondLoginBlocked(c *gin.Context, err error) {
+	var blocked *services.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		logrus.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not process login"})
+		return
+	}
+
+	retryAfter := int(math.Ceil(blocked.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	if errors.Is(err, services.ErrLoginLocked) {
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error(), "retry_after": retryAfter})
+		return
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "retry_after": retryAfter})
+}
+
+func (h *AuthHandler) generateJWT(id uint, email string, role string) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &struct {
-		jwt.RegisteredClaims
-		UserID uint `json:"user_id"`
-	}{
+	claims := &AuthClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   email,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 		UserID: id,
+		Role:   role,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/services"
+	accountService "BankSystem/internal/services/account"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type BeneficiaryHandler struct {
+	beneficiaryService *services.BeneficiaryService
+	authService        *services.AuthService
+	auditService       *services.AuditService
+}
+
+func NewBeneficiaryHandler(beneficiaryService *services.BeneficiaryService, authService *services.AuthService, auditService *services.AuditService) *BeneficiaryHandler {
+	return &BeneficiaryHandler{
+		beneficiaryService: beneficiaryService,
+		authService:        authService,
+		auditService:       auditService,
+	}
+}
+
+// CreateBeneficiary godoc
+// @Summary Сохранение получателя
+// @Description Добавляет в адресную книгу получателя по ID аккаунта или номеру карты под псевдонимом.
+// @Description Номер карты не сохраняется, в ответе возвращается маскированный номер
+// @Tags beneficiary
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateBeneficiaryRequest true "Псевдоним и аккаунт или номер карты"
+// @Success 201 {object} models.Beneficiary
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /beneficiary/create [post]
+func (h *BeneficiaryHandler) CreateBeneficiary(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req dto.CreateBeneficiaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	beneficiary, err := h.beneficiaryService.CreateBeneficiary(user.ID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(beneficiaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionBeneficiaryCreate,
+		EntityType:   models.AuditEntityBeneficiary,
+		EntityID:     strconv.Itoa(int(beneficiary.ID)),
+		After:        beneficiary,
+	})
+
+	c.JSON(http.StatusCreated, beneficiary)
+}
+
+// GetBeneficiaries godoc
+// @Summary Адресная книга
+// @Description Возвращает сохранённых получателей текущего пользователя
+// @Tags beneficiary
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Beneficiary
+// @Failure 500 {object} map[string]string
+// @Router /beneficiary/all [get]
+func (h *BeneficiaryHandler) GetBeneficiaries(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	beneficiaries, err := h.beneficiaryService.GetBeneficiaries(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not load beneficiaries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, beneficiaries)
+}
+
+// DeleteBeneficiary godoc
+// @Summary Удаление получателя
+// @Description Удаляет получателя из адресной книги вместе с шаблонами переводов ему
+// @Tags beneficiary
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID получателя"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /beneficiary/{id}/delete [post]
+func (h *BeneficiaryHandler) DeleteBeneficiary(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	beneficiaryID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	beneficiary, err := h.beneficiaryService.DeleteBeneficiary(beneficiaryID, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(beneficiaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionBeneficiaryDelete,
+		EntityType:   models.AuditEntityBeneficiary,
+		EntityID:     strconv.Itoa(int(beneficiary.ID)),
+		Before:       beneficiary,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Beneficiary deleted"})
+}
+
+// CreateTemplate godoc
+// @Summary Создание шаблона перевода
+// @Description Сохраняет перевод с аккаунта текущего пользователя сохранённому получателю с суммой и комментарием
+// @Tags template
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateTemplateRequest true "Получатель, аккаунт списания, сумма и комментарий"
+// @Success 201 {object} models.PaymentTemplate
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /template/create [post]
+func (h *BeneficiaryHandler) CreateTemplate(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req dto.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.beneficiaryService.CreateTemplate(user.ID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(beneficiaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionTemplateCreate,
+		EntityType:   models.AuditEntityTemplate,
+		EntityID:     strconv.Itoa(int(template.ID)),
+		After:        template,
+	})
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// GetTemplates godoc
+// @Summary Шаблоны переводов
+// @Description Возвращает шаблоны переводов текущего пользователя
+// @Tags template
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.PaymentTemplate
+// @Failure 500 {object} map[string]string
+// @Router /template/all [get]
+func (h *BeneficiaryHandler) GetTemplates(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	templates, err := h.beneficiaryService.GetTemplates(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not load templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// DeleteTemplate godoc
+// @Summary Удаление шаблона перевода
+// @Tags template
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID шаблона"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /template/{id}/delete [post]
+func (h *BeneficiaryHandler) DeleteTemplate(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	templateID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.beneficiaryService.DeleteTemplate(templateID, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(beneficiaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionTemplateDelete,
+		EntityType:   models.AuditEntityTemplate,
+		EntityID:     strconv.Itoa(int(template.ID)),
+		Before:       template,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted"})
+}
+
+// ExecuteTemplate godoc
+// @Summary Перевод по шаблону
+// @Description Выполняет перевод по шаблону одним вызовом. Если аккаунт или карта получателя закрыты, заблокированы
+// @Description или больше не существуют, перевод отклоняется
+// @Tags template
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID шаблона"
+// @Success 200 {object} dto.TemplateExecutionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /template/{id}/execute [post]
+func (h *BeneficiaryHandler) ExecuteTemplate(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	templateID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	execution, err := h.beneficiaryService.ExecuteTemplate(templateID, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(beneficiaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	template := execution.Template
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionTemplateExecute,
+		EntityType:   models.AuditEntityTemplate,
+		EntityID:     strconv.Itoa(int(template.ID)),
+		Details: map[string]interface{}{
+			"beneficiary_id":  template.BeneficiaryID,
+			"from_account_id": template.FromAccountID,
+			"amount":          template.Amount.StringFixed(2),
+		},
+	})
+
+	c.JSON(http.StatusOK, dto.TemplateExecutionResponse{
+		TemplateID:    template.ID,
+		Beneficiary:   execution.Beneficiary.Nickname,
+		FromAccountID: template.FromAccountID,
+		Amount:        template.Amount,
+		Balance:       execution.Balance,
+	})
+}
+
+// beneficiaryErrorStatus — HTTP-статус для ошибок адресной книги и шаблонов переводов
+func beneficiaryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrBeneficiaryNotFound),
+		errors.Is(err, services.ErrTemplateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrBeneficiaryExists):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrBeneficiaryInactive):
+		return http.StatusUnprocessableEntity
+	case accountService.IsStatusError(err),
+		errors.Is(err, accountService.ErrInsufficientFunds),
+		errors.Is(err, accountService.ErrAccountNotFound),
+		errors.Is(err, accountService.ErrSameAccount):
+		return accountErrorStatus(err)
+	}
+	return http.StatusInternalServerError
+}
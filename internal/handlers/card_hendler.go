@@ -2,11 +2,16 @@ package handlers
 
 import (
 	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
 	"BankSystem/internal/repositories"
 	"BankSystem/internal/services"
 	account_service "BankSystem/internal/services/account"
+	"BankSystem/internal/utils/pan"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"net/http"
+	"strconv"
 )
 
 type CardHandler struct {
@@ -16,21 +21,24 @@ type CardHandler struct {
 	authService    *services.AuthService
 	accountRepo    *repositories.AccountRepository
 	cardRepo       *repositories.CardRepository
+	auditService   *services.AuditService
 }
 
-func NewCardHandler(userService *services.UserService, accountService *account_service.AccountService, cardService *services.CardService, authService *services.AuthService, accountRepo *repositories.AccountRepository) *CardHandler {
+func NewCardHandler(userService *services.UserService, accountService *account_service.AccountService, cardService *services.CardService, authService *services.AuthService, accountRepo *repositories.AccountRepository, auditService *services.AuditService) *CardHandler {
 	return &CardHandler{
 		userService:    userService,
 		accountService: accountService,
 		cardService:    cardService,
 		authService:    authService,
 		accountRepo:    accountRepo,
+		auditService:   auditService,
 	}
 }
 
 // CreateCard godoc
 // @Summary Создание новой карты
-// @Description Создаёт новую карту для текущего пользователя
+// @Description Выпускает физическую или виртуальную карту на аккаунт текущего пользователя. У аккаунта может быть несколько карт.
+// @Description Для виртуальной карты можно задать одноразовость, лимит на весь срок и срок действия
 // @Tags card
 // @Security BearerAuth
 // @Produce json
@@ -58,13 +66,40 @@ func (h *CardHandler) CreateCard(c *gin.Context) {
 		return
 	}
 
-	card, err := h.cardService.GenerateCard(req.AccountID)
+	card, err := h.cardService.GenerateCard(req.AccountID, services.CardOptions{
+		ProductCode: req.Product,
+		Kind:        req.Kind,
+		SingleUse:   req.SingleUse,
+		SpendingCap: optionalAmount(req.SpendingCap),
+		ExpiresAt:   req.ExpiresAt,
+	})
 	if err != nil {
+		if status := cardErrorStatus(err); status != http.StatusInternalServerError {
+			c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not generate card: " + err.Error()})
 		return
 	}
 
+	// Номер карты и CVV в журнал не попадают
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionCardIssue,
+		EntityType:   models.AuditEntityCard,
+		EntityID:     strconv.Itoa(int(card.ID)),
+		Details: map[string]interface{}{
+			"account_id": req.AccountID,
+			"kind":       card.Kind,
+			"single_use": card.SingleUse,
+			"last4":      pan.Last4(card.CardNumber),
+			"expired_at": card.ExpiredAt.Format("01/06"),
+		},
+	})
+
 	response := dto.NewCardResponse{
+		ID:     card.ID,
+		Kind:   card.Kind,
 		Number: card.CardNumber,
 		CVV:    card.Cvv,
 		Expiry: card.ExpiredAt.Format("01/06"),
@@ -73,16 +108,35 @@ func (h *CardHandler) CreateCard(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
+// GetCardProducts godoc
+// @Summary Карточные продукты
+// @Description Возвращает продукты, по которым можно выпустить карту: платёжная система, BIN, длина номера и срок действия
+// @Tags card
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.CardProduct
+// @Failure 500 {object} map[string]string
+// @Router /card/products [get]
+func (h *CardHandler) GetCardProducts(c *gin.Context) {
+	products, err := h.cardService.GetProducts()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not load card products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
 // GetCards godoc
 // @Summary Получить все карты пользователя
-// @Description Возвращает список всех карт с балансом по аккаунтам
+// @Description Возвращает список всех карт с балансом по аккаунтам и суммой оплат по карте за текущий месяц (spent_this_month)
 // @Tags card
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {array} dto.CardWithBalance
+// @Success 200 {array} dto.CardResponse
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /cards [get]
+// @Router /card/all [get]
 func (h *CardHandler) GetCards(c *gin.Context) {
 	user, err := h.authService.GetCurrentUser(c)
 	if err != nil || user == nil {
@@ -105,18 +159,25 @@ func (h *CardHandler) GetCards(c *gin.Context) {
 
 // PayWithCard godoc
 // @Summary Оплата с помощью карты
-// @Description Выполняет оплату, проверяя данные карты и списывая средства
+// @Description Авторизует оплату: мерчант, номер карты, срок действия, CVV, статус карты, статус аккаунта, лимиты и баланс.
+// @Description С tokenize=true владельцу мерчанта возвращается токен карты, по которому он может проводить повторные оплаты.
+// @Description Оплата выше порога CARD_OTP_THRESHOLD возвращает 202 с кодом 1A и payment_id: владельцу карты отправлен
+// @Description одноразовый код, оплата завершается через /payment/{id}/confirm.
+// @Description При отказе возвращает код ответа: 03, 05, 14, 41, 51, 54, 57, 61, 62, 65, 82, 96
 // @Tags payment
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param request body dto.CardPaymentRequest true "Данные карты и сумма"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} dto.CardPaymentResponse
+// @Success 202 {object} dto.CardPaymentResponse
 // @Failure 400 {object} map[string]string
-// @Failure 403 {object} map[string]string
 // @Failure 402 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /payment/card [post]
+// @Router /card/payment [post]
 func (h *CardHandler) PayWithCard(c *gin.Context) {
 	user, err := h.authService.GetCurrentUser(c)
 	if err != nil || user == nil {
@@ -130,15 +191,628 @@ func (h *CardHandler) PayWithCard(c *gin.Context) {
 		return
 	}
 
-	newBalance, err := h.cardService.PayWithCard(req)
+	hold, newBalance, err := h.cardService.PayWithCard(req, user.ID)
+	code := services.AuthorizationCode(err)
+	details := map[string]interface{}{
+		"last4":  pan.Last4(req.CardNumber),
+		"amount": decimal.NewFromFloat(req.Amount).StringFixed(2),
+		"code":   code,
+		"result": "approved",
+	}
+	token := ""
+	challenged := err == nil && hold.Status == models.HoldStatusChallenged
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "payment failed: " + err.Error()})
+		details["result"] = "declined"
+		details["reason"] = err.Error()
+	} else if challenged {
+		// Токен выдаётся при подтверждении оплаты
+		details["result"] = "challenged"
+		details["code"] = services.AuthCodeAuthenticationRequired
+		details["payment_id"] = hold.ID
+	} else if req.Tokenize {
+		token = issueToken(h.cardService, hold, user.ID, details)
+	}
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionCardPayment,
+		EntityType:   models.AuditEntityCard,
+		Details:      details,
+	})
+
+	if err != nil {
+		message := err.Error()
+		if code == services.AuthCodeSystemMalfunction {
+			message = "payment failed"
+		}
+		c.AbortWithStatusJSON(authorizationStatus(code), gin.H{"code": code, "error": message})
+		return
+	}
+
+	if challenged {
+		c.JSON(http.StatusAccepted, dto.CardPaymentResponse{
+			Result:    false,
+			Code:      services.AuthCodeAuthenticationRequired,
+			Message:   "confirmation code sent to the card owner",
+			PaymentID: hold.ID,
+		})
 		return
 	}
 
 	response := dto.CardPaymentResponse{
 		Result:  true,
+		Code:    code,
 		Message: "payment done, new balance: " + newBalance.String(),
+		Token:   token,
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// TransferByCard godoc
+// @Summary Перевод с карты на карту
+// @Description Переводит деньги с карты текущего пользователя на карту получателя по её номеру.
+// @Description Проверяются номер, срок действия, CVV и статус карты отправителя, статус аккаунта и баланс, затем карта получателя.
+// @Description При отказе возвращает код ответа: 05, 14, 41, 51, 54, 57, 62, 82, 96
+// @Tags transfer
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CardTransferRequest true "Карта отправителя, номер карты получателя и сумма"
+// @Success 200 {object} dto.CardTransferResponse
+// @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /transfer/card [post]
+func (h *CardHandler) TransferByCard(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req dto.CardTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.cardService.TransferByCard(req, user.ID)
+	code := services.AuthorizationCode(err)
+	details := map[string]interface{}{
+		"last4":           pan.Last4(req.CardNumber),
+		"recipient_last4": pan.Last4(req.RecipientCardNumber),
+		"amount":          decimal.NewFromFloat(req.Amount).StringFixed(2),
+		"code":            code,
+	}
+	entityID := ""
+	if err != nil {
+		details["reason"] = err.Error()
+	} else {
+		entityID = strconv.Itoa(int(transfer.CardID))
+		details["recipient_card_id"] = transfer.RecipientCardID
+	}
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionCardTransfer,
+		EntityType:   models.AuditEntityCard,
+		EntityID:     entityID,
+		Details:      details,
+	})
+
+	if err != nil {
+		message := err.Error()
+		if code == services.AuthCodeSystemMalfunction {
+			message = "transfer failed"
+		}
+		c.AbortWithStatusJSON(authorizationStatus(code), gin.H{"code": code, "error": message})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CardTransferResponse{
+		Code:          code,
+		Amount:        transfer.Amount,
+		Balance:       transfer.Balance,
+		RecipientCard: pan.Mask(req.RecipientCardNumber),
+	})
+}
+
+// BlockCard godoc
+// @Summary Блокировка карты
+// @Description Временно блокирует карту текущего пользователя, оплаты по ней отклоняются
+// @Tags card
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID карты"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /card/{id}/block [post]
+func (h *CardHandler) BlockCard(c *gin.Context) {
+	h.changeCardStatus(c, models.CardStatusBlocked)
+}
+
+// UnblockCard godoc
+// @Summary Разблокировка карты
+// @Description Снимает блокировку, установленную владельцем карты
+// @Tags card
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID карты"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /card/{id}/unblock [post]
+func (h *CardHandler) UnblockCard(c *gin.Context) {
+	h.changeCardStatus(c, models.CardStatusActive)
+}
+
+// ReportLost godoc
+// @Summary Сообщить об утере карты
+// @Description Окончательно блокирует карту. Вместо неё можно выпустить новую через /card/{id}/reissue
+// @Tags card
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID карты"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /card/{id}/lost [post]
+func (h *CardHandler) ReportLost(c *gin.Context) {
+	h.changeCardStatus(c, models.CardStatusLost)
+}
+
+// ReissueCard godoc
+// @Summary Перевыпуск карты
+// @Description Выпускает на тот же аккаунт карту с новым номером и CVV, прежняя карта перестаёт действовать
+// @Tags card
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID карты"
+// @Success 201 {object} dto.NewCardResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /card/{id}/reissue [post]
+func (h *CardHandler) ReissueCard(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	cardID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	card, err := h.cardService.Reissue(cardID, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionCardReissue,
+		EntityType:   models.AuditEntityCard,
+		EntityID:     strconv.Itoa(int(cardID)),
+		Details: map[string]interface{}{
+			"new_card_id": card.ID,
+			"account_id":  card.AccountId,
+			"last4":       pan.Last4(card.CardNumber),
+		},
+	})
+
+	c.JSON(http.StatusCreated, dto.NewCardResponse{
+		Number: card.CardNumber,
+		CVV:    card.Cvv,
+		Expiry: card.ExpiredAt.Format("01/06"),
+	})
+}
+
+// GetCardLimits godoc
+// @Summary Лимиты карты
+// @Description Возвращает лимиты карты и израсходованные в текущих периодах суммы
+// @Tags card
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID карты"
+// @Success 200 {object} dto.CardLimitsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /card/{id}/limits [get]
+func (h *CardHandler) GetCardLimits(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	cardID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limits, err := h.cardService.GetLimits(cardID, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// SetCardPIN godoc
+// @Summary Установка PIN-кода
+// @Description Устанавливает PIN-код карты, у которой его ещё нет. PIN хранится в виде хеша
+// @Tags card
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID карты"
+// @Param request body dto.SetPINRequest true "PIN из 4 цифр"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /card/{id}/pin [post]
+func (h *CardHandler) SetCardPIN(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	cardID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.SetPINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.cardService.SetPIN(cardID, user.ID, req.PIN); err != nil {
+		c.AbortWithStatusJSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionCardPINSet,
+		EntityType:   models.AuditEntityCard,
+		EntityID:     strconv.Itoa(int(cardID)),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "PIN set"})
+}
+
+// ChangeCardPIN godoc
+// @Summary Смена PIN-кода
+// @Description Меняет PIN-код после проверки текущего. После трёх неверных попыток подряд карта блокируется
+// @Tags card
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID карты"
+// @Param request body dto.ChangePINRequest true "Текущий и новый PIN"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /card/{id}/pin [put]
+func (h *CardHandler) ChangeCardPIN(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	cardID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.ChangePINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.cardService.ChangePIN(cardID, user.ID, req.OldPIN, req.NewPIN)
+	details := map[string]interface{}{"result": "changed"}
+	if err != nil {
+		details["result"] = "rejected"
+		details["reason"] = err.Error()
+	}
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionCardPINChange,
+		EntityType:   models.AuditEntityCard,
+		EntityID:     strconv.Itoa(int(cardID)),
+		Details:      details,
+	})
+
+	if err != nil {
+		c.AbortWithStatusJSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "PIN changed"})
+}
+
+// GetCardTokens godoc
+// @Summary Токены карты
+// @Description Возвращает токены карты, выданные мерчантам для повторных оплат
+// @Tags card
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID карты"
+// @Success 200 {array} models.CardToken
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /card/{id}/tokens [get]
+func (h *CardHandler) GetCardTokens(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	cardID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.cardService.GetTokens(cardID, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// GetCardTransactions godoc
+// @Summary Операции по карте
+// @Description Возвращает постранично, новыми первыми, операции по карте текущего пользователя: оплаты, возвраты,
+// @Description снятия наличных, комиссии и переводы с карты. Для оплат и возвратов указаны мерчант и описание
+// @Tags card
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID карты"
+// @Param limit query int false "Количество записей (по умолчанию 50, максимум 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.Transaction
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /card/{id}/transactions [get]
+func (h *CardHandler) GetCardTransactions(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	cardID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset := parsePagination(c)
+	transactions, err := h.cardService.GetTransactions(cardID, user.ID, limit, offset)
+	if err != nil {
+		c.AbortWithStatusJSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+}
+
+// RevokeCardToken godoc
+// @Summary Отзыв токена карты
+// @Description Отзывает токен, после чего мерчант не может проводить по нему оплаты
+// @Tags card
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID карты"
+// @Param token_id path int true "ID токена"
+// @Success 200 {object} models.CardToken
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /card/{id}/tokens/{token_id}/revoke [post]
+func (h *CardHandler) RevokeCardToken(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	cardID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tokenID, err := parseIDParam(c, "token_id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.cardService.RevokeToken(cardID, tokenID, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionCardTokenRevoke,
+		EntityType:   models.AuditEntityCard,
+		EntityID:     strconv.Itoa(int(cardID)),
+		Details: map[string]interface{}{
+			"token_id":    token.ID,
+			"merchant_id": token.MerchantID,
+		},
+	})
+
+	c.JSON(http.StatusOK, token)
+}
+
+// SetCardLimits godoc
+// @Summary Установка лимитов карты
+// @Description Заменяет лимиты карты: на одну оплату, за день, за месяц и количество оплат в час. Не переданный лимит снимается
+// @Tags card
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID карты"
+// @Param request body dto.CardLimitsRequest true "Лимиты"
+// @Success 200 {object} dto.CardLimitsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /card/{id}/limits [put]
+func (h *CardHandler) SetCardLimits(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	cardID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.CardLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limits := services.CardLimits{
+		PerTransaction:  optionalAmount(req.PerTransaction),
+		Daily:           optionalAmount(req.Daily),
+		Monthly:         optionalAmount(req.Monthly),
+		PaymentsPerHour: req.PaymentsPerHour,
+	}
+	if _, err := h.cardService.SetLimits(cardID, user.ID, limits); err != nil {
+		c.AbortWithStatusJSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionCardLimitsChange,
+		EntityType:   models.AuditEntityCard,
+		EntityID:     strconv.Itoa(int(cardID)),
+		After:        req,
+	})
+
+	response, err := h.cardService.GetLimits(cardID, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *CardHandler) changeCardStatus(c *gin.Context, status string) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	cardID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous, err := h.cardService.ChangeStatus(cardID, user.ID, status)
+	if err != nil {
+		c.AbortWithStatusJSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionCardStatusChange,
+		EntityType:   models.AuditEntityCard,
+		EntityID:     strconv.Itoa(int(cardID)),
+		Details:      map[string]interface{}{"from": previous, "to": status},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Card status changed to " + status})
+}
+
+// cardErrorStatus — HTTP-статус для ошибок операций с картой
+func cardErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCardNotFound), errors.Is(err, services.ErrCardTokenNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidCardStatusTransition),
+		errors.Is(err, services.ErrCardNotReissuable),
+		errors.Is(err, services.ErrPINAlreadySet),
+		errors.Is(err, services.ErrPINNotSet):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrIncorrectPIN):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrPINTriesExceeded):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidCardLimits),
+		errors.Is(err, services.ErrInvalidCardOptions),
+		errors.Is(err, services.ErrInvalidCardExpiry),
+		errors.Is(err, services.ErrCardProductNotFound):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// authorizationStatus — HTTP-статус для кода ответа авторизации
+func authorizationStatus(code string) int {
+	switch code {
+	case services.AuthCodeApproved:
+		return http.StatusOK
+	case services.AuthCodeInvalidCard:
+		return http.StatusNotFound
+	case services.AuthCodeInvalidMerchant, services.AuthCodeExpiredCard, services.AuthCodeCVVFailed, services.AuthCodeIncorrectPIN:
+		return http.StatusUnprocessableEntity
+	case services.AuthCodeLostCard, services.AuthCodeRestrictedCard, services.AuthCodeNotPermitted, services.AuthCodePINTriesExceeded:
+		return http.StatusForbidden
+	case services.AuthCodeInsufficientFunds, services.AuthCodeExceedsAmountLimit, services.AuthCodeExceedsFrequency, services.AuthCodeDoNotHonor:
+		return http.StatusPaymentRequired
+	}
+	return http.StatusInternalServerError
+}
+
+func optionalAmount(value *float64) *decimal.Decimal {
+	if value == nil {
+		return nil
+	}
+	amount := decimal.NewFromFloat(*value)
+	return &amount
+}
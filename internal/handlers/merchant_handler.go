@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type MerchantHandler struct {
+	merchantService *services.MerchantService
+	authService     *services.AuthService
+	auditService    *services.AuditService
+}
+
+func NewMerchantHandler(merchantService *services.MerchantService, authService *services.AuthService, auditService *services.AuditService) *MerchantHandler {
+	return &MerchantHandler{
+		merchantService: merchantService,
+		authService:     authService,
+		auditService:    auditService,
+	}
+}
+
+// CreateMerchant godoc
+// @Summary Регистрация мерчанта
+// @Description Создаёт мерчанта текущего пользователя. Оплаты картой зачисляются на указанный расчётный аккаунт
+// @Tags merchant
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateMerchantRequest true "Название и расчётный аккаунт"
+// @Success 201 {object} models.Merchant
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /merchant/create [post]
+func (h *MerchantHandler) CreateMerchant(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req dto.CreateMerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchant, err := h.merchantService.Create(user.ID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSettlementAccount) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not create merchant"})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionMerchantCreate,
+		EntityType:   models.AuditEntityMerchant,
+		EntityID:     strconv.Itoa(int(merchant.ID)),
+		After:        merchant,
+	})
+
+	c.JSON(http.StatusCreated, merchant)
+}
+
+// GetMerchants godoc
+// @Summary Мерчанты пользователя
+// @Description Возвращает мерчантов текущего пользователя
+// @Tags merchant
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Merchant
+// @Failure 500 {object} map[string]string
+// @Router /merchant/all [get]
+func (h *MerchantHandler) GetMerchants(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	merchants, err := h.merchantService.GetByOwnerID(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not load merchants"})
+		return
+	}
+
+	c.JSON(http.StatusOK, merchants)
+}
+
+// GetMerchantPayments godoc
+// @Summary Оплаты мерчанта
+// @Description Возвращает оплаты картой в пользу мерчанта постранично, новые первыми. Доступно владельцу мерчанта
+// @Tags merchant
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID мерчанта"
+// @Param limit query int false "Количество записей (по умолчанию 50, максимум 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} dto.PaymentHoldResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /merchant/{id}/payments [get]
+func (h *MerchantHandler) GetMerchantPayments(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	merchantID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset := parsePagination(c)
+	holds, err := h.merchantService.GetPayments(merchantID, user.ID, limit, offset)
+	if err != nil {
+		if errors.Is(err, services.ErrMerchantNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not load payments"})
+		return
+	}
+
+	response := make([]dto.PaymentHoldResponse, 0, len(holds))
+	for i := range holds {
+		response = append(response, newPaymentHoldResponse(&holds[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
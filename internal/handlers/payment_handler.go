@@ -0,0 +1,415 @@
+package handlers
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/services"
+	accountService "BankSystem/internal/services/account"
+	"BankSystem/internal/utils/pan"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+)
+
+type PaymentHandler struct {
+	cardService  *services.CardService
+	authService  *services.AuthService
+	auditService *services.AuditService
+}
+
+func NewPaymentHandler(cardService *services.CardService, authService *services.AuthService, auditService *services.AuditService) *PaymentHandler {
+	return &PaymentHandler{
+		cardService:  cardService,
+		authService:  authService,
+		auditService: auditService,
+	}
+}
+
+// Authorize godoc
+// @Summary Авторизация оплаты картой
+// @Description Проверяет карту так же, как /card/payment, и резервирует сумму на аккаунте карты без списания.
+// @Description Резерв нужно списать через /payment/{id}/capture или отменить через /payment/{id}/void до истечения срока.
+// @Description Оплата выше порога CARD_OTP_THRESHOLD возвращает 202 и резерв в статусе challenged до подтверждения кодом
+// @Tags payment
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CardPaymentRequest true "Данные карты и сумма"
+// @Success 201 {object} dto.PaymentHoldResponse
+// @Success 202 {object} dto.PaymentHoldResponse
+// @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /payment/authorize [post]
+func (h *PaymentHandler) Authorize(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req dto.CardPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, err := h.cardService.AuthorizePayment(req, user.ID)
+	code := services.AuthorizationCode(err)
+	details := map[string]interface{}{
+		"last4":  pan.Last4(req.CardNumber),
+		"amount": decimal.NewFromFloat(req.Amount).StringFixed(2),
+		"code":   code,
+	}
+	entityID := ""
+	if err != nil {
+		details["reason"] = err.Error()
+	} else {
+		entityID = strconv.Itoa(int(hold.ID))
+		if hold.Status == models.HoldStatusChallenged {
+			details["code"] = services.AuthCodeAuthenticationRequired
+		}
+	}
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionPaymentAuthorize,
+		EntityType:   models.AuditEntityPayment,
+		EntityID:     entityID,
+		Details:      details,
+	})
+
+	if err != nil {
+		message := err.Error()
+		if code == services.AuthCodeSystemMalfunction {
+			message = "authorization failed"
+		}
+		c.AbortWithStatusJSON(authorizationStatus(code), gin.H{"code": code, "error": message})
+		return
+	}
+
+	response := newPaymentHoldResponse(hold)
+	if hold.Status == models.HoldStatusChallenged {
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// Confirm godoc
+// @Summary Подтверждение оплаты одноразовым кодом
+// @Description Проверяет код, отправленный владельцу карты при оплате выше порога. Оплата через /payment/card
+// @Description после подтверждения списывается, резерв через /payment/authorize переходит в статус authorized.
+// @Description После CARD_OTP_MAX_ATTEMPTS неверных кодов или по истечении CARD_OTP_TTL оплата отменяется.
+// @Description Доступно пользователю, инициировавшему оплату
+// @Tags payment
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID оплаты"
+// @Param request body dto.PaymentConfirmRequest true "Одноразовый код"
+// @Success 200 {object} dto.PaymentHoldResponse
+// @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /payment/{id}/confirm [post]
+func (h *PaymentHandler) Confirm(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	holdID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.PaymentConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, err := h.cardService.ConfirmPayment(holdID, user.ID, req.Code)
+	details := map[string]interface{}{"result": "confirmed"}
+	token := ""
+	if err != nil {
+		details["result"] = "failed"
+		details["reason"] = err.Error()
+	} else {
+		details["status"] = hold.Status
+		if req.Tokenize {
+			token = issueToken(h.cardService, hold, user.ID, details)
+		}
+	}
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionPaymentConfirm,
+		EntityType:   models.AuditEntityPayment,
+		EntityID:     strconv.Itoa(int(holdID)),
+		Details:      details,
+	})
+
+	if err != nil {
+		var authErr *services.AuthorizationError
+		if errors.As(err, &authErr) {
+			c.AbortWithStatusJSON(authorizationStatus(authErr.Code), gin.H{"code": authErr.Code, "error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := newPaymentHoldResponse(hold)
+	response.Token = token
+	c.JSON(http.StatusOK, response)
+}
+
+// PayWithToken godoc
+// @Summary Оплата мерчантом по токену карты
+// @Description Списывает сумму по токену, выданному мерчанту при оплате с tokenize=true, без номера карты и CVV.
+// @Description Проверяются срок действия и статус карты, статус аккаунта, лимиты и баланс. Доступно владельцу мерчанта
+// @Tags payment
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.TokenPaymentRequest true "Мерчант, токен и сумма"
+// @Success 200 {object} dto.PaymentHoldResponse
+// @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /payment/token [post]
+func (h *PaymentHandler) PayWithToken(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req dto.TokenPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, _, err := h.cardService.PayWithToken(req, user.ID)
+	code := services.AuthorizationCode(err)
+	details := map[string]interface{}{
+		"merchant_id": req.MerchantID,
+		"amount":      decimal.NewFromFloat(req.Amount).StringFixed(2),
+		"code":        code,
+	}
+	entityID := ""
+	if err != nil {
+		details["reason"] = err.Error()
+	} else {
+		entityID = strconv.Itoa(int(hold.ID))
+		details["token_id"] = hold.TokenID
+	}
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionPaymentToken,
+		EntityType:   models.AuditEntityPayment,
+		EntityID:     entityID,
+		Details:      details,
+	})
+
+	if err != nil {
+		message := err.Error()
+		if code == services.AuthCodeSystemMalfunction {
+			message = "payment failed"
+		}
+		c.AbortWithStatusJSON(authorizationStatus(code), gin.H{"code": code, "error": message})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPaymentHoldResponse(hold))
+}
+
+// Capture godoc
+// @Summary Списание зарезервированной суммы
+// @Description Списывает резерв полностью или частично и зачисляет сумму мерчанту. Остаток резерва освобождается. Доступно владельцу мерчанта.
+// @Description С tokenize=true после списания возвращает токен карты для повторных оплат
+// @Tags payment
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID оплаты"
+// @Param request body dto.PaymentAmountRequest false "Сумма частичного списания и выдача токена"
+// @Success 200 {object} dto.PaymentHoldResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /payment/{id}/capture [post]
+func (h *PaymentHandler) Capture(c *gin.Context) {
+	h.handle(c, models.AuditActionPaymentCapture, true, func(userID uint, holdID uint, amount *decimal.Decimal) (*models.PaymentHold, error) {
+		return h.cardService.CapturePayment(holdID, userID, amount)
+	})
+}
+
+// Void godoc
+// @Summary Отмена резерва
+// @Description Отменяет ещё не списанный резерв и освобождает сумму на аккаунте карты. Доступно владельцу мерчанта
+// @Tags payment
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID оплаты"
+// @Success 200 {object} dto.PaymentHoldResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /payment/{id}/void [post]
+func (h *PaymentHandler) Void(c *gin.Context) {
+	h.handle(c, models.AuditActionPaymentVoid, false, func(userID uint, holdID uint, _ *decimal.Decimal) (*models.PaymentHold, error) {
+		return h.cardService.VoidPayment(holdID, userID)
+	})
+}
+
+// Refund godoc
+// @Summary Возврат списанной оплаты
+// @Description Возвращает на аккаунт карты списанную сумму полностью или частично за счёт расчётного аккаунта мерчанта. Доступно владельцу мерчанта
+// @Tags payment
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID оплаты"
+// @Param request body dto.PaymentAmountRequest false "Сумма частичного возврата"
+// @Success 200 {object} dto.PaymentHoldResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /payment/{id}/refund [post]
+func (h *PaymentHandler) Refund(c *gin.Context) {
+	h.handle(c, models.AuditActionPaymentRefund, false, func(userID uint, holdID uint, amount *decimal.Decimal) (*models.PaymentHold, error) {
+		return h.cardService.RefundPayment(holdID, userID, amount)
+	})
+}
+
+// handle — общий разбор запроса к существующей оплате, запись в аудит и ответ.
+// tokenize разрешает выдать по запросу токен карты после операции.
+func (h *PaymentHandler) handle(c *gin.Context, action string, tokenize bool, operation func(userID uint, holdID uint, amount *decimal.Decimal) (*models.PaymentHold, error)) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	holdID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.PaymentAmountRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	amount := optionalAmount(req.Amount)
+	hold, err := operation(user.ID, holdID, amount)
+	if err != nil {
+		c.AbortWithStatusJSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	details := map[string]interface{}{
+		"status":          hold.Status,
+		"captured_amount": hold.CapturedAmount.StringFixed(2),
+		"refunded_amount": hold.RefundedAmount.StringFixed(2),
+	}
+	if amount != nil {
+		details["amount"] = amount.StringFixed(2)
+	}
+	token := ""
+	if tokenize && req.Tokenize {
+		token = issueToken(h.cardService, hold, user.ID, details)
+	}
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       action,
+		EntityType:   models.AuditEntityPayment,
+		EntityID:     strconv.Itoa(int(holdID)),
+		Details:      details,
+	})
+
+	response := newPaymentHoldResponse(hold)
+	response.Token = token
+	c.JSON(http.StatusOK, response)
+}
+
+func newPaymentHoldResponse(hold *models.PaymentHold) dto.PaymentHoldResponse {
+	code := services.AuthCodeApproved
+	if hold.Status == models.HoldStatusChallenged {
+		code = services.AuthCodeAuthenticationRequired
+	}
+	return dto.PaymentHoldResponse{
+		ID:             hold.ID,
+		MerchantID:     hold.MerchantID,
+		TokenID:        hold.TokenID,
+		Code:           code,
+		Status:         hold.Status,
+		Amount:         hold.Amount,
+		CapturedAmount: hold.CapturedAmount,
+		RefundedAmount: hold.RefundedAmount,
+		Currency:       hold.Currency,
+		ExpiresAt:      hold.ExpiresAt,
+		CreatedAt:      hold.CreatedAt,
+	}
+}
+
+// issueToken выдаёт владельцу мерчанта токен карты после списания оплаты. Оплата уже проведена,
+// поэтому ошибка выдачи токена не отменяет её, а только попадает в журнал.
+func issueToken(cardService *services.CardService, hold *models.PaymentHold, userID uint, details map[string]interface{}) string {
+	token, cardToken, err := cardService.IssueToken(hold, userID)
+	if err != nil {
+		details["token_error"] = err.Error()
+		return ""
+	}
+	details["token_id"] = cardToken.ID
+	return token
+}
+
+// paymentErrorStatus — HTTP-статус для ошибок операций с резервом
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrHoldNotFound), errors.Is(err, services.ErrMerchantNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrHoldNotAuthorized),
+		errors.Is(err, services.ErrHoldExpired),
+		errors.Is(err, services.ErrHoldNotRefundable),
+		errors.Is(err, services.ErrHoldNotChallenged),
+		errors.Is(err, services.ErrOTPExpired):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrOTPAttemptsExceeded):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrCaptureExceedsHold),
+		errors.Is(err, services.ErrRefundExceedsCaptured),
+		errors.Is(err, services.ErrIncorrectOTP):
+		return http.StatusUnprocessableEntity
+	case accountService.IsStatusError(err),
+		errors.Is(err, accountService.ErrAccountNotFound),
+		errors.Is(err, accountService.ErrInsufficientFunds):
+		return accountErrorStatus(err)
+	}
+	return http.StatusInternalServerError
+}
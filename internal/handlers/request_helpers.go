@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"BankSystem/internal/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// auditContext собирает данные об инициаторе запроса для журнала аудита
+func auditContext(c *gin.Context) services.AuditContext {
+	actx := services.AuditContext{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: c.GetString("request_id"),
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uint); ok && id != 0 {
+			actx.ActorID = &id
+		}
+	}
+	return actx
+}
+
+// parsePagination читает limit и offset из query-параметров
+func parsePagination(c *gin.Context) (int, int) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	offset, err := strconv.Atoi(c.Query("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// parseIDParam читает положительный числовой идентификатор из пути
+func parseIDParam(c *gin.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("invalid " + name)
+	}
+	return uint(id), nil
+}
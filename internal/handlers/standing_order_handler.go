@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/services"
+	accountService "BankSystem/internal/services/account"
+	"BankSystem/internal/utils/schedule"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type StandingOrderHandler struct {
+	orderService *services.StandingOrderService
+	authService  *services.AuthService
+	auditService *services.AuditService
+}
+
+func NewStandingOrderHandler(orderService *services.StandingOrderService, authService *services.AuthService, auditService *services.AuditService) *StandingOrderHandler {
+	return &StandingOrderHandler{
+		orderService: orderService,
+		authService:  authService,
+		auditService: auditService,
+	}
+}
+
+// CreateStandingOrder godoc
+// @Summary Создание регулярного перевода
+// @Description Создаёт поручение на перевод с аккаунта текущего пользователя по расписанию: daily, weekly, monthly
+// @Description или cron-выражение из пяти полей в UTC. При нехватке средств запуск пропускается (skip) или повторяется (retry)
+// @Tags standing-order
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateStandingOrderRequest true "Аккаунты, сумма и расписание"
+// @Success 201 {object} models.StandingOrder
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /standing-order/create [post]
+func (h *StandingOrderHandler) CreateStandingOrder(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req dto.CreateStandingOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.orderService.Create(user.ID, req)
+	if err != nil {
+		c.AbortWithStatusJSON(standingOrderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionStandingOrderCreate,
+		EntityType:   models.AuditEntityStandingOrder,
+		EntityID:     strconv.Itoa(int(order.ID)),
+		After:        order,
+	})
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// GetStandingOrders godoc
+// @Summary Регулярные переводы пользователя
+// @Description Возвращает поручения текущего пользователя с датой следующего запуска
+// @Tags standing-order
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.StandingOrder
+// @Failure 500 {object} map[string]string
+// @Router /standing-order/all [get]
+func (h *StandingOrderHandler) GetStandingOrders(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	orders, err := h.orderService.GetByUserID(user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not load standing orders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+// GetExecutions godoc
+// @Summary История запусков регулярного перевода
+// @Description Возвращает попытки перевода по поручению постранично, новые первыми
+// @Tags standing-order
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID поручения"
+// @Param limit query int false "Количество записей (по умолчанию 50, максимум 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.StandingOrderExecution
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /standing-order/{id}/executions [get]
+func (h *StandingOrderHandler) GetExecutions(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	orderID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset := parsePagination(c)
+	executions, err := h.orderService.GetExecutions(orderID, user.ID, limit, offset)
+	if err != nil {
+		c.AbortWithStatusJSON(standingOrderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, executions)
+}
+
+// CancelStandingOrder godoc
+// @Summary Отмена регулярного перевода
+// @Description Отменяет активное поручение текущего пользователя
+// @Tags standing-order
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID поручения"
+// @Success 200 {object} models.StandingOrder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /standing-order/{id}/cancel [post]
+func (h *StandingOrderHandler) CancelStandingOrder(c *gin.Context) {
+	user, err := h.authService.GetCurrentUser(c)
+	if err != nil || user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	orderID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.orderService.Cancel(orderID, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(standingOrderErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.auditService.TryRecord(services.AuditEntry{
+		AuditContext: auditContext(c),
+		Action:       models.AuditActionStandingOrderCancel,
+		EntityType:   models.AuditEntityStandingOrder,
+		EntityID:     strconv.Itoa(int(order.ID)),
+	})
+
+	c.JSON(http.StatusOK, order)
+}
+
+// standingOrderErrorStatus — HTTP-статус для ошибок операций с регулярными переводами
+func standingOrderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrStandingOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrStandingOrderNotActive):
+		return http.StatusConflict
+	case errors.Is(err, schedule.ErrInvalidSchedule),
+		errors.Is(err, services.ErrInvalidOrderStart),
+		errors.Is(err, services.ErrInvalidOrderEnd):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInvalidOrderRecipient):
+		return http.StatusUnprocessableEntity
+	case accountService.IsStatusError(err),
+		errors.Is(err, accountService.ErrAccountNotFound),
+		errors.Is(err, accountService.ErrSameAccount):
+		return accountErrorStatus(err)
+	}
+	return http.StatusInternalServerError
+}
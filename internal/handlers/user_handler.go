@@ -28,7 +28,7 @@ func NewUserHandler(repo *repositories.UserRepository, authService *services.Aut
 // @Produce json
 // @Success 200 {object} models.User
 // @Failure 401 {object} map[string]string
-// @Router /user/me [get]
+// @Router /user/profile [get]
 func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	user, err := h.authService.GetCurrentUser(c)
 	if err != nil || user == nil {
@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+// Job — периодическая фоновая задача
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler запускает зарегистрированные задачи с заданным интервалом до отмены контекста.
+// Первый запуск выполняется сразу после старта, ошибки задачи логируются и не прерывают расписание.
+type Scheduler struct {
+	jobs []Job
+	log  *logrus.Logger
+	wg   sync.WaitGroup
+}
+
+func NewScheduler(log *logrus.Logger) *Scheduler {
+	return &Scheduler{log: log}
+}
+
+// Every регистрирует задачу. Задачи с неположительным интервалом не запускаются.
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			s.log.Info("job " + job.Name + " is disabled")
+			continue
+		}
+
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Wait дожидается завершения всех задач после отмены контекста
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Errorf("job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		s.log.Error("job " + job.Name + " failed: " + err.Error())
+	}
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"BankSystem/internal/handlers"
+	"BankSystem/internal/repositories"
+	"BankSystem/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"net/http"
@@ -11,7 +13,9 @@ import (
 
 var jwtKey = []byte(os.Getenv("JWT_SECRET"))
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware проверяет токен доступа и загружает пользователя: токены, выпущенные до смены пароля,
+// отклоняются на всех защищённых маршрутах, а роль для проверки прав берётся из БД
+func AuthMiddleware(userRepo *repositories.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -21,17 +25,33 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		claims := &jwt.RegisteredClaims{}
+		claims := &handlers.AuthClaims{}
 		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 			return handlers.JwtKey, nil
 		})
 
-		if err != nil || !token.Valid {
+		// Токен ссылки из письма не является токеном доступа
+		if err != nil || !token.Valid || claims.Purpose != "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
 
+		user, err := userRepo.FindByEmail(claims.Subject)
+		if err != nil || user == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+
+		// Токены, выпущенные до смены пароля, больше не действуют
+		if user.PasswordChangedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(*user.PasswordChangedAt)) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": services.ErrSessionExpired.Error()})
+			return
+		}
+
 		c.Set("email", claims.Subject)
+		c.Set("user_id", claims.UserID)
+		// Роль берётся из БД, а не из токена, чтобы понижение роли действовало сразу
+		c.Set("role", user.Role)
 		c.Next()
 	}
 }
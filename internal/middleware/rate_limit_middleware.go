@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"BankSystem/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// RateLimitMiddleware ограничивает частоту запросов к группе маршрутов scope.
+// Запросы считаются по пользователю из токена, а без него — по IP клиента, поэтому для
+// защищённых маршрутов middleware подключается после AuthMiddleware.
+// Если хранилище недоступно, запрос пропускается: ограничение не должно останавливать сервис.
+func RateLimitMiddleware(store ratelimit.Store, scope string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limit.Enabled() {
+			c.Next()
+			return
+		}
+
+		result, err := store.Take(c.Request.Context(), scope+":"+rateLimitKey(c), limit)
+		if err != nil {
+			logrus.Warn("rate limiter unavailable: " + err.Error())
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, retry later"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitKey — ключ бакета. IP берётся из c.ClientIP(), который учитывает X-Forwarded-For только от TRUSTED_PROXIES,
+// поэтому подмена заголовка не даёт клиенту нового бакета.
+func rateLimitKey(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uint); ok && id != 0 {
+			return "user:" + strconv.Itoa(int(id))
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"BankSystem/internal/security"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RequirePermission пропускает запрос, только если текущая роль пользователя (из БД, см. AuthMiddleware) даёт указанное право.
+// Должен подключаться после AuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := c.Get("role")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		if !security.HasPermission(role.(string), permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+
+		c.Next()
+	}
+}
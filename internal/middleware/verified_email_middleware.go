@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"BankSystem/internal/repositories"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// VerifiedEmailMiddleware пропускает запрос только для пользователей с подтверждённым email.
+// Должен подключаться после AuthMiddleware.
+func VerifiedEmailMiddleware(userRepo *repositories.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email, exists := c.Get("email")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		user, err := userRepo.FindByEmail(email.(string))
+		if err != nil || user == nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		if !user.IsEmailVerified() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Email is not verified"})
+			return
+		}
+
+		c.Next()
+	}
+}
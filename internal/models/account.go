@@ -3,11 +3,59 @@ package models
 import (
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"time"
 )
 
+const (
+	// AccountStatusActive — все операции разрешены
+	AccountStatusActive = "active"
+	// AccountStatusFrozen — списания запрещены, зачисления разрешены
+	AccountStatusFrozen = "frozen"
+	// AccountStatusBlocked — запрещены любые операции
+	AccountStatusBlocked = "blocked"
+	// AccountStatusClosed — аккаунт закрыт окончательно
+	AccountStatusClosed = "closed"
+)
+
+// accountStatusTransitions — допустимые смены статуса оператором. Закрытие выполняется отдельно.
+var accountStatusTransitions = map[string][]string{
+	AccountStatusActive:  {AccountStatusFrozen, AccountStatusBlocked},
+	AccountStatusFrozen:  {AccountStatusActive, AccountStatusBlocked},
+	AccountStatusBlocked: {AccountStatusActive, AccountStatusFrozen},
+}
+
 type Account struct {
 	gorm.Model
 	UserID   uint            `db:"user_id"  json:"user_id"`
 	Balance  decimal.Decimal `db:"balance"  json:"balance"`
 	Currency string          `db:"currency"  json:"currency"`
+	Status   string          `db:"status"  json:"status"`
+	ClosedAt *time.Time      `db:"closed_at"  json:"closed_at,omitempty"`
+	// HoldAmount — сумма, зарезервированная неподтверждёнными оплатами картой
+	HoldAmount decimal.Decimal `db:"hold_amount"  json:"hold_amount"`
+}
+
+// AvailableBalance — баланс за вычетом зарезервированных сумм
+func (a *Account) AvailableBalance() decimal.Decimal {
+	return a.Balance.Sub(a.HoldAmount)
+}
+
+// CanDebit — разрешены ли списания с аккаунта
+func (a *Account) CanDebit() bool {
+	return a.Status == AccountStatusActive
+}
+
+// CanCredit — разрешены ли зачисления на аккаунт
+func (a *Account) CanCredit() bool {
+	return a.Status == AccountStatusActive || a.Status == AccountStatusFrozen
+}
+
+// CanTransitTo — можно ли перевести аккаунт в указанный статус
+func (a *Account) CanTransitTo(status string) bool {
+	for _, allowed := range accountStatusTransitions[a.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+const (
+	AuditActionLogin                 = "auth.login"
+	AuditActionLoginFailed           = "auth.login_failed"
+	AuditActionRegister              = "auth.register"
+	AuditActionPasswordReset         = "auth.password_reset"
+	AuditActionLoginLockout          = "auth.lockout"
+	AuditActionIPLockout             = "auth.ip_lockout"
+	AuditActionAccountUnlock         = "auth.unlock"
+	AuditActionAdminUsersList        = "admin.users.list"
+	AuditActionAdminAccountView      = "admin.account.view"
+	AuditActionAdminTransactionsView = "admin.account.transactions"
+	AuditActionAdminAuditView        = "admin.audit.view"
+	AuditActionAdminAuditVerify      = "admin.audit.verify"
+	AuditActionAdminGrant            = "admin.grant"
+	AuditActionAccountCreate         = "account.create"
+	AuditActionAccountDeposit        = "account.deposit"
+	AuditActionAccountWithdraw       = "account.withdraw"
+	AuditActionAccountStatusChange   = "account.status_change"
+	AuditActionAccountClose          = "account.close"
+	AuditActionAccountSetDefault     = "account.set_default"
+	AuditActionTransfer              = "transfer.create"
+	AuditActionCardTransfer          = "transfer.card"
+	AuditActionCardIssue             = "card.issue"
+	AuditActionCardPayment           = "card.payment"
+	AuditActionCardStatusChange      = "card.status_change"
+	AuditActionCardReissue           = "card.reissue"
+	AuditActionCardLimitsChange      = "card.limits_change"
+	AuditActionPaymentAuthorize      = "payment.authorize"
+	AuditActionPaymentCapture        = "payment.capture"
+	AuditActionPaymentVoid           = "payment.void"
+	AuditActionPaymentRefund         = "payment.refund"
+	AuditActionPaymentConfirm        = "payment.confirm"
+	AuditActionMerchantCreate        = "merchant.create"
+	AuditActionPaymentToken          = "payment.token"
+	AuditActionCardTokenRevoke       = "card.token_revoke"
+	AuditActionCardPINSet            = "card.pin_set"
+	AuditActionCardPINChange         = "card.pin_change"
+	AuditActionATMWithdraw           = "atm.withdraw"
+	AuditActionStandingOrderCreate   = "standing_order.create"
+	AuditActionStandingOrderCancel   = "standing_order.cancel"
+	AuditActionBeneficiaryCreate     = "beneficiary.create"
+	AuditActionBeneficiaryDelete     = "beneficiary.delete"
+	AuditActionTemplateCreate        = "template.create"
+	AuditActionTemplateDelete        = "template.delete"
+	AuditActionTemplateExecute       = "template.execute"
+	AuditEntityUser                  = "user"
+	AuditEntityIP                    = "ip"
+	AuditEntityAccount               = "account"
+	AuditEntityCard                  = "card"
+	AuditEntityPayment               = "payment"
+	AuditEntityMerchant              = "merchant"
+	AuditEntityStandingOrder         = "standing_order"
+	AuditEntityBeneficiary           = "beneficiary"
+	AuditEntityTemplate              = "template"
+	AuditEntityAuditLog              = "audit_log"
+)
+
+// AuditLog — запись журнала аудита. Записи только добавляются: каждая хранит хеш предыдущей,
+// поэтому изменение или удаление записи в середине журнала обнаруживается при проверке цепочки.
+type AuditLog struct {
+	ID          uint      `db:"id" json:"id"`
+	ActorID     *uint     `db:"actor_id" json:"actor_id"`
+	Action      string    `db:"action" json:"action"`
+	EntityType  string    `db:"entity_type" json:"entity_type"`
+	EntityID    string    `db:"entity_id" json:"entity_id"`
+	IP          string    `db:"ip" json:"ip"`
+	UserAgent   string    `db:"user_agent" json:"user_agent"`
+	RequestID   string    `db:"request_id" json:"request_id"`
+	Details     string    `db:"details" gorm:"type:jsonb" json:"details"`
+	BeforeValue *string   `db:"before_value" gorm:"type:jsonb" json:"before_value"`
+	AfterValue  *string   `db:"after_value" gorm:"type:jsonb" json:"after_value"`
+	PrevHash    *string   `db:"prev_hash" json:"prev_hash"`
+	Hash        *string   `db:"hash" json:"hash"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	// BeneficiaryKindAccount — получатель задан аккаунтом банка
+	BeneficiaryKindAccount = "account"
+	// BeneficiaryKindCard — получатель задан номером карты банка
+	BeneficiaryKindCard = "card"
+)
+
+// Beneficiary — сохранённый получатель переводов пользователя. Для карты хранится ссылка на карту
+// и маскированный номер, полный номер не сохраняется.
+type Beneficiary struct {
+	gorm.Model
+	UserID     uint    `db:"user_id" json:"user_id"`
+	Nickname   string  `db:"nickname" json:"nickname"`
+	Kind       string  `db:"kind" json:"kind"`
+	AccountID  *uint   `db:"account_id" json:"account_id,omitempty"`
+	CardID     *uint   `db:"card_id" json:"-"`
+	CardMasked *string `db:"card_masked" json:"card_masked,omitempty"`
+}
+
+// PaymentTemplate — шаблон перевода сохранённому получателю с суммой и комментарием
+type PaymentTemplate struct {
+	gorm.Model
+	UserID        uint            `db:"user_id" json:"user_id"`
+	Name          string          `db:"name" json:"name"`
+	BeneficiaryID uint            `db:"beneficiary_id" json:"beneficiary_id"`
+	FromAccountID uint            `db:"from_account_id" json:"from_account_id"`
+	Amount        decimal.Decimal `db:"amount" json:"amount"`
+	Comment       *string         `db:"comment" json:"comment,omitempty"`
+}
@@ -1,14 +1,89 @@
 package models
 
 import (
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 	"time"
 )
 
+const (
+	// CardStatusActive — карта работает
+	CardStatusActive = "active"
+	// CardStatusBlocked — временно заблокирована владельцем, может быть разблокирована
+	CardStatusBlocked = "blocked"
+	// CardStatusLost — утеряна, пользоваться нельзя, можно только перевыпустить
+	CardStatusLost = "lost"
+	// CardStatusExpired — истёк срок действия
+	CardStatusExpired = "expired"
+	// CardStatusClosed — заменена перевыпущенной картой
+	CardStatusClosed = "closed"
+)
+
+const (
+	// CardKindPhysical — пластиковая карта
+	CardKindPhysical = "physical"
+	// CardKindVirtual — виртуальная карта для оплат онлайн
+	CardKindVirtual = "virtual"
+)
+
+// cardStatusTransitions — смены статуса, доступные владельцу карты
+var cardStatusTransitions = map[string][]string{
+	CardStatusActive:  {CardStatusBlocked, CardStatusLost},
+	CardStatusBlocked: {CardStatusActive, CardStatusLost},
+}
+
 type Card struct {
 	gorm.Model
-	AccountId  uint      `db:"account_id" json:"account_id"`
-	CardNumber string    `db:"card_number" json:"card_number"`
-	Cvv        string    `db:"cvv" json:"cvv"`
-	ExpiredAt  time.Time `db:"expired_at" json:"expired_at"`
+	AccountId  uint   `db:"account_id" json:"account_id"`
+	ProductID  *uint  `db:"product_id" json:"product_id,omitempty"`
+	CardNumber string `db:"card_number" json:"card_number"`
+	// CardKeyID — ключ из набора, которым зашифрован номер; nil — устаревшее шифрование pgcrypto
+	CardKeyID *string `db:"card_key_id" json:"-"`
+	// CardNumberIndex — HMAC номера карты для поиска без расшифровки
+	CardNumberIndex *string    `db:"card_number_index" json:"-"`
+	Cvv             string     `db:"cvv" json:"cvv"`
+	ExpiredAt       time.Time  `db:"expired_at" json:"expired_at"`
+	Status          string     `db:"status" json:"status"`
+	StatusChangedAt *time.Time `db:"status_changed_at" json:"status_changed_at,omitempty"`
+	ReissuedFromID  *uint      `db:"reissued_from_id" json:"reissued_from_id,omitempty"`
+	// Лимиты, заданные владельцем; nil — без ограничения
+	PerTransactionLimit *decimal.Decimal `db:"per_transaction_limit" json:"per_transaction_limit,omitempty"`
+	DailyLimit          *decimal.Decimal `db:"daily_limit" json:"daily_limit,omitempty"`
+	MonthlyLimit        *decimal.Decimal `db:"monthly_limit" json:"monthly_limit,omitempty"`
+	HourlyPaymentsLimit *int             `db:"hourly_payments_limit" json:"hourly_payments_limit,omitempty"`
+	Kind                string           `db:"kind" json:"kind"`
+	// SingleUse — виртуальная карта закрывается после первой успешной оплаты
+	SingleUse bool `db:"single_use" json:"single_use"`
+	// SpendingCap — сумма, которую можно потратить по карте за весь срок действия; nil — без ограничения
+	SpendingCap *decimal.Decimal `db:"spending_cap" json:"spending_cap,omitempty"`
+	// PinHash — bcrypt-хеш PIN-кода; nil — PIN не установлен
+	PinHash *string `db:"pin_hash" json:"-"`
+	// PinAttempts — неверные попытки ввода PIN подряд
+	PinAttempts int `db:"pin_attempts" json:"-"`
+}
+
+// IsUsable — можно ли проводить операции по карте
+func (c *Card) IsUsable(now time.Time) bool {
+	return c.Status == CardStatusActive && now.Before(c.ExpiredAt)
+}
+
+// CanTransitTo — можно ли перевести карту в указанный статус
+func (c *Card) CanTransitTo(status string) bool {
+	for _, allowed := range cardStatusTransitions[c.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPIN — установлен ли PIN-код
+func (c *Card) HasPIN() bool {
+	return c.PinHash != nil
+}
+
+// CanReissue — статус карты допускает перевыпуск. Утерянная карта остаётся lost и после перевыпуска,
+// поэтому наличие замены проверяется отдельно по ReissuedFromID новых карт.
+func (c *Card) CanReissue() bool {
+	return c.Status != CardStatusClosed
 }
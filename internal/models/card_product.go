@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	PaymentSystemMir        = "mir"
+	PaymentSystemVisa       = "visa"
+	PaymentSystemMastercard = "mastercard"
+)
+
+// CardProduct — карточный продукт: платёжная система, BIN выпускаемых номеров, их длина и срок действия карт
+type CardProduct struct {
+	gorm.Model
+	Code           string `db:"code" json:"code"`
+	Name           string `db:"name" json:"name"`
+	PaymentSystem  string `db:"payment_system" json:"payment_system"`
+	BIN            string `db:"bin" gorm:"column:bin" json:"bin"`
+	PANLength      int    `db:"pan_length" gorm:"column:pan_length" json:"pan_length"`
+	ValidityMonths int    `db:"validity_months" json:"validity_months"`
+	// Active — по неактивному продукту новые карты не выпускаются
+	Active bool `db:"active" json:"active"`
+}
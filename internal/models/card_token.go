@@ -0,0 +1,26 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	// CardTokenStatusActive — мерчант может проводить оплаты по токену
+	CardTokenStatusActive = "active"
+	// CardTokenStatusRevoked — токен отозван владельцем карты
+	CardTokenStatusRevoked = "revoked"
+)
+
+// CardToken — токен карты, выданный одному мерчанту для повторных оплат без номера карты и CVV.
+// Хранится только хеш токена.
+type CardToken struct {
+	gorm.Model
+	CardID     uint       `db:"card_id" json:"card_id"`
+	MerchantID uint       `db:"merchant_id" json:"merchant_id"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	Last4      string     `db:"last4" gorm:"column:last4" json:"last4"`
+	Status     string     `db:"status" json:"status"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+const (
+	ThrottleScopeAccount = "account"
+	ThrottleScopeIP      = "ip"
+)
+
+// LoginThrottle — счётчик неудачных попыток входа для аккаунта или IP-адреса
+type LoginThrottle struct {
+	ID             uint       `db:"id" json:"id"`
+	Scope          string     `db:"scope" json:"scope"`
+	Key            string     `db:"key" json:"key"`
+	FailedAttempts int        `db:"failed_attempts" json:"failed_attempts"`
+	LastFailedAt   *time.Time `db:"last_failed_at" json:"last_failed_at"`
+	LockedUntil    *time.Time `db:"locked_until" json:"locked_until"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "gorm.io/gorm"
+
+// Merchant — получатель оплат картой. Оплаты зачисляются на его расчётный аккаунт.
+type Merchant struct {
+	gorm.Model
+	OwnerUserID         uint   `db:"owner_user_id" json:"owner_user_id"`
+	Name                string `db:"name" json:"name"`
+	SettlementAccountID uint   `db:"settlement_account_id" json:"settlement_account_id"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	// HoldStatusChallenged — средства зарезервированы, оплата ждёт подтверждения одноразовым кодом
+	HoldStatusChallenged = "challenged"
+	// HoldStatusAuthorized — средства зарезервированы и ждут списания
+	HoldStatusAuthorized = "authorized"
+	// HoldStatusCaptured — средства списаны полностью или частично
+	HoldStatusCaptured = "captured"
+	// HoldStatusVoided — резерв отменён до списания
+	HoldStatusVoided = "voided"
+	// HoldStatusExpired — резерв снят автоматически по истечении срока
+	HoldStatusExpired = "expired"
+	// HoldStatusRefunded — списанная сумма возвращена полностью
+	HoldStatusRefunded = "refunded"
+)
+
+// PaymentHold — двухфазная оплата картой: резерв средств на аккаунте и его последующее списание
+type PaymentHold struct {
+	gorm.Model
+	CardID          uint  `db:"card_id" json:"card_id"`
+	AccountID       uint  `db:"account_id" json:"account_id"`
+	MerchantID      *uint `db:"merchant_id" json:"merchant_id,omitempty"`
+	InitiatorUserID *uint `db:"initiator_user_id" json:"initiator_user_id,omitempty"`
+	// TokenID — оплата проведена мерчантом по токену карты
+	TokenID        *uint           `db:"token_id" json:"token_id,omitempty"`
+	Amount         decimal.Decimal `db:"amount" json:"amount"`
+	CapturedAmount decimal.Decimal `db:"captured_amount" json:"captured_amount"`
+	RefundedAmount decimal.Decimal `db:"refunded_amount" json:"refunded_amount"`
+	Currency       string          `db:"currency" json:"currency"`
+	Status         string          `db:"status" json:"status"`
+	ExpiresAt      time.Time       `db:"expires_at" json:"expires_at"`
+	CapturedAt     *time.Time      `db:"captured_at" json:"captured_at,omitempty"`
+	ReleasedAt     *time.Time      `db:"released_at" json:"released_at,omitempty"`
+	// OtpHash — хеш одноразового кода подтверждения, OtpAttempts — неверные попытки его ввода
+	OtpHash     *string `db:"otp_hash" json:"-"`
+	OtpAttempts int     `db:"otp_attempts" json:"-"`
+	// CaptureOnConfirm — после подтверждения кода резерв сразу списывается (одноэтапная оплата)
+	CaptureOnConfirm bool `db:"capture_on_confirm" json:"-"`
+}
+
+// IsReserved — резерв ещё удерживает сумму на аккаунте
+func (h *PaymentHold) IsReserved() bool {
+	return h.Status == HoldStatusAuthorized || h.Status == HoldStatusChallenged
+}
+
+// Refundable — сумма, которую ещё можно вернуть
+func (h *PaymentHold) Refundable() decimal.Decimal {
+	return h.CapturedAmount.Sub(h.RefundedAmount)
+}
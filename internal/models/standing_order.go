@@ -0,0 +1,68 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	// StandingOrderStatusActive — поручение исполняется по расписанию
+	StandingOrderStatusActive = "active"
+	// StandingOrderStatusCancelled — поручение отменено владельцем или из-за закрытия аккаунта
+	StandingOrderStatusCancelled = "cancelled"
+	// StandingOrderStatusCompleted — запусков больше не будет: прошла дата окончания
+	StandingOrderStatusCompleted = "completed"
+
+	// OnInsufficientFundsSkip — при нехватке средств запуск пропускается до следующего по расписанию
+	OnInsufficientFundsSkip = "skip"
+	// OnInsufficientFundsRetry — при нехватке средств запуск повторяется через заданный интервал
+	OnInsufficientFundsRetry = "retry"
+
+	ExecutionStatusSucceeded = "succeeded"
+	// ExecutionStatusRetrying — перевод не выполнен, запуск будет повторён
+	ExecutionStatusRetrying = "retrying"
+	// ExecutionStatusSkipped — перевод не выполнен из-за нехватки средств, запуск пропущен
+	ExecutionStatusSkipped = "skipped"
+	ExecutionStatusFailed  = "failed"
+)
+
+// StandingOrder — регулярный перевод с аккаунта пользователя по расписанию
+type StandingOrder struct {
+	gorm.Model
+	UserID              uint            `db:"user_id" json:"user_id"`
+	FromAccountID       uint            `db:"from_account_id" json:"from_account_id"`
+	ToAccountID         uint            `db:"to_account_id" json:"to_account_id"`
+	Amount              decimal.Decimal `db:"amount" json:"amount"`
+	Description         *string         `db:"description" json:"description,omitempty"`
+	Recurrence          string          `db:"recurrence" json:"recurrence"`
+	StartAt             time.Time       `db:"start_at" json:"start_at"`
+	EndAt               *time.Time      `db:"end_at" json:"end_at,omitempty"`
+	OnInsufficientFunds string          `db:"on_insufficient_funds" json:"on_insufficient_funds"`
+	Status              string          `db:"status" json:"status"`
+	NextRunAt           *time.Time      `db:"next_run_at" json:"next_run_at,omitempty"`
+	RetryAt             *time.Time      `db:"retry_at" json:"retry_at,omitempty"`
+	// Attempts — число неудачных попыток текущего запуска
+	Attempts  int        `db:"attempts" json:"attempts"`
+	LastRunAt *time.Time `db:"last_run_at" json:"last_run_at,omitempty"`
+}
+
+// DueAt — время ближайшей попытки: повтор, если он назначен, иначе плановый запуск
+func (o *StandingOrder) DueAt() *time.Time {
+	if o.RetryAt != nil {
+		return o.RetryAt
+	}
+	return o.NextRunAt
+}
+
+// StandingOrderExecution — одна попытка исполнить поручение
+type StandingOrderExecution struct {
+	ID              uint            `db:"id" json:"id"`
+	StandingOrderID uint            `db:"standing_order_id" json:"standing_order_id"`
+	ScheduledAt     time.Time       `db:"scheduled_at" json:"scheduled_at"`
+	Attempt         int             `db:"attempt" json:"attempt"`
+	Amount          decimal.Decimal `db:"amount" json:"amount"`
+	Status          string          `db:"status" json:"status"`
+	Error           *string         `db:"error" json:"error,omitempty"`
+	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
+}
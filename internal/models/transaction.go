@@ -12,4 +12,9 @@ type Transaction struct {
 	Amount          decimal.Decimal `db:"amount"  json:"amount"`
 	TransactionType string          `db:"transaction_type"  json:"transaction_type"`
 	Currency        string          `db:"currency"  json:"currency"`
+	CardID          *uint           `db:"card_id"  json:"card_id,omitempty"`
+	HoldID          *uint           `db:"hold_id"  json:"hold_id,omitempty"`
+	// MerchantID и Description заполняются для операций по картам
+	MerchantID  *uint   `db:"merchant_id"  json:"merchant_id,omitempty"`
+	Description *string `db:"description"  json:"description,omitempty"`
 }
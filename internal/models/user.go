@@ -1,10 +1,30 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	RoleCustomer = "customer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
 
 type User struct {
 	gorm.Model
-	Username string `gorm:"unique" db:"username" json:"username"`
-	Email    string `gorm:"unique" db:"email" json:"email"`
-	Password string `db:"password" json:"password"`
+	Username           string     `gorm:"unique" db:"username" json:"username"`
+	Email              string     `gorm:"unique" db:"email" json:"email"`
+	Password           string     `db:"password" json:"password"`
+	Role               string     `db:"role" json:"role"`
+	PasswordChangedAt  *time.Time `db:"password_changed_at" json:"-"`
+	EmailVerifiedAt    *time.Time `db:"email_verified_at" json:"email_verified_at"`
+	VerificationSentAt *time.Time `db:"verification_sent_at" json:"-"`
+	// DefaultAccountID — аккаунт для зачисления переводов по username или email
+	DefaultAccountID *uint `db:"default_account_id" json:"default_account_id"`
+}
+
+// IsEmailVerified — подтверждён ли email пользователя
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
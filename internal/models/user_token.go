@@ -0,0 +1,21 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeAccountUnlock = "account_unlock"
+)
+
+// UserToken — одноразовый токен пользователя, в БД хранится только sha256-хеш
+type UserToken struct {
+	gorm.Model
+	UserID    uint       `db:"user_id" json:"user_id"`
+	Purpose   string     `db:"purpose" json:"purpose"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at"`
+}
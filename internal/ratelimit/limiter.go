@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit — параметры корзины токенов: не больше Requests запросов подряд,
+// после чего корзина равномерно пополняется до Requests за Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Result — итог попытки взять токен
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Store хранит состояние корзин. Take атомарно пополняет корзину key и забирает из неё один токен.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket — состояние одной корзины
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newBucket(limit Limit, now time.Time) bucket {
+	return bucket{tokens: float64(limit.Requests), updatedAt: now}
+}
+
+// take пополняет корзину за время, прошедшее с прошлого обращения, и пытается списать токен
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	rate := limit.rate()
+	capacity := float64(limit.Requests)
+
+	if elapsed := now.Sub(b.updatedAt); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed.Seconds()*rate)
+	}
+	b.updatedAt = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return Result{Allowed: true, Remaining: int(b.tokens)}
+	}
+
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return Result{Allowed: false, Remaining: 0, RetryAfter: wait}
+}
+
+// fullAt — момент, когда корзина пополнится полностью. После него её можно удалить:
+// она не отличается от новой.
+func (b *bucket) fullAt(limit Limit) time.Time {
+	missing := float64(limit.Requests) - b.tokens
+	return b.updatedAt.Add(time.Duration(missing / limit.rate() * float64(time.Second)))
+}
+
+// rate — скорость пополнения, токенов в секунду
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Enabled — лимит задан; нулевые значения отключают ограничение
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
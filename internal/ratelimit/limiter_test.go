@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimitEnabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+		want  bool
+	}{
+		{"enabled", Limit{Requests: 5, Period: time.Minute}, true},
+		{"no requests", Limit{Requests: 0, Period: time.Minute}, false},
+		{"no period", Limit{Requests: 5, Period: 0}, false},
+		{"negative", Limit{Requests: -1, Period: time.Minute}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limit.Enabled(); got != tt.want {
+				t.Errorf("Enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBucketTake(t *testing.T) {
+	limit := Limit{Requests: 2, Period: time.Second}
+	start := time.Date(2024, 3, 8, 10, 0, 0, 0, time.UTC)
+
+	// Шаги выполняются по порядку над одной корзиной
+	steps := []struct {
+		name          string
+		at            time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{"full bucket", 0, true, 1, 0},
+		{"last token", 0, true, 0, 0},
+		{"empty bucket", 0, false, 0, 500 * time.Millisecond},
+		{"partially refilled", 250 * time.Millisecond, false, 0, 250 * time.Millisecond},
+		{"one token refilled", 500 * time.Millisecond, true, 0, 0},
+		{"refill is capped by capacity", time.Hour, true, 1, 0},
+	}
+	b := newBucket(limit, start)
+	for _, step := range steps {
+		result := b.take(limit, start.Add(step.at))
+		if result.Allowed != step.wantAllowed || result.Remaining != step.wantRemaining || result.RetryAfter != step.wantRetry {
+			t.Fatalf("%s: take() = %+v, want allowed=%v remaining=%d retry=%v",
+				step.name, result, step.wantAllowed, step.wantRemaining, step.wantRetry)
+		}
+	}
+}
+
+func TestBucketFullAt(t *testing.T) {
+	limit := Limit{Requests: 4, Period: 4 * time.Second}
+	start := time.Date(2024, 3, 8, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		takes int
+		want  time.Duration
+	}{
+		{"untouched", 0, 0},
+		{"one taken", 1, time.Second},
+		{"drained", 4, 4 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBucket(limit, start)
+			for i := 0; i < tt.takes; i++ {
+				b.take(limit, start)
+			}
+			if got := b.fullAt(limit); !got.Equal(start.Add(tt.want)) {
+				t.Errorf("fullAt() = %v, want %v", got, start.Add(tt.want))
+			}
+		})
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 1, Period: time.Hour}
+	ctx := context.Background()
+
+	steps := []struct {
+		key  string
+		want bool
+	}{
+		{"login:ip:10.0.0.1", true},
+		{"login:ip:10.0.0.1", false},
+		{"login:ip:10.0.0.2", true},
+		{"auth:ip:10.0.0.1", true},
+	}
+	for _, step := range steps {
+		result, err := store.Take(ctx, step.key, limit)
+		if err != nil {
+			t.Fatalf("Take(%q) error: %v", step.key, err)
+		}
+		if result.Allowed != step.want {
+			t.Fatalf("Take(%q) allowed = %v, want %v", step.key, result.Allowed, step.want)
+		}
+	}
+}
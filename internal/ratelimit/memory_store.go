@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cleanupInterval — как часто удалять полностью пополнившиеся корзины
+const cleanupInterval = time.Minute
+
+type memoryBucket struct {
+	bucket
+	expiresAt time.Time
+}
+
+// MemoryStore хранит корзины в памяти процесса. Подходит для одного экземпляра приложения.
+type MemoryStore struct {
+	mu          sync.Mutex
+	buckets     map[string]*memoryBucket
+	lastCleanup time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:     make(map[string]*memoryBucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanup(now)
+
+	b, exists := s.buckets[key]
+	if !exists {
+		b = &memoryBucket{bucket: newBucket(limit, now)}
+		s.buckets[key] = b
+	}
+	result := b.take(limit, now)
+	b.expiresAt = b.fullAt(limit)
+	return result, nil
+}
+
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		return
+	}
+	s.lastCleanup = now
+
+	for key, b := range s.buckets {
+		if now.After(b.expiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+// PostgresStore хранит корзины в таблице rate_limit_buckets, чтобы лимит был общим
+// для всех экземпляров приложения. Строка корзины блокируется на время пересчёта.
+type PostgresStore struct {
+	db          *gorm.DB
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db, lastCleanup: time.Now()}
+}
+
+type bucketRow struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.cleanup(ctx)
+
+	var result Result
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+
+		err := tx.Exec(`
+            INSERT INTO rate_limit_buckets (key, tokens, updated_at, expires_at)
+            VALUES (?, ?, ?, ?)
+            ON CONFLICT (key) DO NOTHING
+        `, key, limit.Requests, now, now).Error
+		if err != nil {
+			return err
+		}
+
+		var row bucketRow
+		err = tx.Raw("SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = ? FOR UPDATE", key).Scan(&row).Error
+		if err != nil {
+			return err
+		}
+
+		b := bucket{tokens: row.Tokens, updatedAt: row.UpdatedAt}
+		result = b.take(limit, now)
+
+		return tx.Exec(
+			"UPDATE rate_limit_buckets SET tokens = ?, updated_at = ?, expires_at = ? WHERE key = ?",
+			b.tokens, b.updatedAt, b.fullAt(limit), key,
+		).Error
+	})
+	return result, err
+}
+
+// cleanup не чаще раза в минуту удаляет полностью пополнившиеся корзины
+func (s *PostgresStore) cleanup(ctx context.Context) {
+	now := time.Now()
+
+	s.mu.Lock()
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mu.Unlock()
+
+	s.db.WithContext(ctx).Exec("DELETE FROM rate_limit_buckets WHERE expires_at < ?", now.UTC())
+}
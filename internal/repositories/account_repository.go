@@ -6,6 +6,7 @@ import (
 	"BankSystem/internal/models"
 	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AccountRepository struct {
@@ -50,7 +51,7 @@ func (r *AccountRepository) FindByIdAndUserID(id uint, userId uint) (*models.Acc
 
 func (r *AccountRepository) FindAllByUserID(userID uint) ([]*models.Account, error) {
 	var accounts []*models.Account
-	result := r.db.Where("user_id = ?", userID).Find(&accounts)
+	result := r.db.Where("user_id = ?", userID).Order("id").Find(&accounts)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -61,10 +62,10 @@ func (r *AccountRepository) Update(model *models.Account) error {
 	return r.db.Save(model).Error
 }
 
-// FindByIDWithLock — получение аккаунта с блокировкой строки
-func (r *AccountRepository) FindByIDWithLock(id uint) (*models.Account, error) {
+// FindByIDWithLock — получение аккаунта с блокировкой строки до конца транзакции tx
+func (r *AccountRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*models.Account, error) {
 	var account models.Account
-	result := r.db.Where("id = ?", id).Set("gorm:query_option", "FOR UPDATE").First(&account)
+	result := tx.Where("id = ?", id).Clauses(clause.Locking{Strength: "UPDATE"}).First(&account)
 	if result.Error != nil {
 		return nil, result.Error
 	}
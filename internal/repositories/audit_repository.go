@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"BankSystem/internal/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// auditChainLockID — ключ advisory-блокировки, сериализующей добавление записей в цепочку
+const auditChainLockID = 31_000_001
+
+// AuditLogFilter — условия выборки журнала аудита, пустые поля не учитываются
+type AuditLogFilter struct {
+	ActorID    *uint
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Append добавляет запись в конец цепочки. build получает хеш последней записи и возвращает новую запись.
+func (r *AuditRepository) Append(build func(prevHash string) (*models.AuditLog, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockID).Error; err != nil {
+			return err
+		}
+
+		var prevHash string
+		err := tx.Raw("SELECT COALESCE(hash, '') FROM audit_logs ORDER BY id DESC LIMIT 1").Scan(&prevHash).Error
+		if err != nil {
+			return err
+		}
+
+		entry, err := build(prevHash)
+		if err != nil {
+			return err
+		}
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *AuditRepository) Find(filter AuditLogFilter, limit int, offset int) ([]models.AuditLog, error) {
+	query := r.db.Model(&models.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var entries []models.AuditLog
+	result := query.Order("id DESC").Limit(limit).Offset(offset).Find(&entries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entries, nil
+}
+
+// FindInBatches — обход всего журнала в порядке добавления
+func (r *AuditRepository) FindInBatches(batchSize int, fn func([]models.AuditLog) error) error {
+	var batch []models.AuditLog
+	return r.db.FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
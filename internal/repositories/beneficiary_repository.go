@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"BankSystem/internal/models"
+	"errors"
+	"gorm.io/gorm"
+)
+
+// BeneficiaryRepository — сохранённые получатели и шаблоны переводов
+type BeneficiaryRepository struct {
+	db *gorm.DB
+}
+
+func NewBeneficiaryRepository(db *gorm.DB) *BeneficiaryRepository {
+	return &BeneficiaryRepository{db: db}
+}
+
+func (r *BeneficiaryRepository) Create(beneficiary *models.Beneficiary) error {
+	return r.db.Create(beneficiary).Error
+}
+
+func (r *BeneficiaryRepository) FindByIDAndUserID(id uint, userID uint) (*models.Beneficiary, error) {
+	var beneficiary models.Beneficiary
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).First(&beneficiary)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &beneficiary, result.Error
+}
+
+func (r *BeneficiaryRepository) FindByNickname(userID uint, nickname string) (*models.Beneficiary, error) {
+	var beneficiary models.Beneficiary
+	result := r.db.Where("user_id = ? AND nickname = ?", userID, nickname).First(&beneficiary)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &beneficiary, result.Error
+}
+
+func (r *BeneficiaryRepository) FindAllByUserID(userID uint) ([]models.Beneficiary, error) {
+	var beneficiaries []models.Beneficiary
+	result := r.db.Where("user_id = ?", userID).Order("nickname").Find(&beneficiaries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return beneficiaries, nil
+}
+
+// Delete удаляет получателя вместе с его шаблонами
+func (r *BeneficiaryRepository) Delete(beneficiary *models.Beneficiary) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("beneficiary_id = ?", beneficiary.ID).Delete(&models.PaymentTemplate{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(beneficiary).Error
+	})
+}
+
+func (r *BeneficiaryRepository) CreateTemplate(template *models.PaymentTemplate) error {
+	return r.db.Create(template).Error
+}
+
+func (r *BeneficiaryRepository) FindTemplateByIDAndUserID(id uint, userID uint) (*models.PaymentTemplate, error) {
+	var template models.PaymentTemplate
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).First(&template)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &template, result.Error
+}
+
+func (r *BeneficiaryRepository) FindTemplatesByUserID(userID uint) ([]models.PaymentTemplate, error) {
+	var templates []models.PaymentTemplate
+	result := r.db.Where("user_id = ?", userID).Order("name").Find(&templates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return templates, nil
+}
+
+func (r *BeneficiaryRepository) DeleteTemplate(template *models.PaymentTemplate) error {
+	return r.db.Delete(template).Error
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"BankSystem/internal/models"
+	"errors"
+	"gorm.io/gorm"
+)
+
+type CardProductRepository struct {
+	db *gorm.DB
+}
+
+func NewCardProductRepository(db *gorm.DB) *CardProductRepository {
+	return &CardProductRepository{db: db}
+}
+
+func (r *CardProductRepository) FindByID(id uint) (*models.CardProduct, error) {
+	var product models.CardProduct
+	result := r.db.First(&product, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &product, result.Error
+}
+
+// FindActiveByCode — продукт, по которому можно выпускать карты
+func (r *CardProductRepository) FindActiveByCode(code string) (*models.CardProduct, error) {
+	var product models.CardProduct
+	result := r.db.Where("code = ? AND active", code).First(&product)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &product, result.Error
+}
+
+func (r *CardProductRepository) FindAllActive() ([]models.CardProduct, error) {
+	var products []models.CardProduct
+	result := r.db.Where("active").Order("id").Find(&products)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return products, nil
+}
@@ -7,6 +7,8 @@ import (
 	"BankSystem/internal/models"
 	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
 type CardRepository struct {
@@ -22,15 +24,6 @@ func (r *CardRepository) Create(entity *models.Card) error {
 	return result.Error
 }
 
-func (r *CardRepository) FindByAccountId(accountId uint) (*models.Card, error) {
-	var card models.Card
-	result := r.db.Where("account_id = ?", accountId).First(&card)
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, nil
-	}
-	return &card, result.Error
-}
-
 func (r *CardRepository) FindByCardNumber(cardNumber string) (*models.Card, error) {
 	var card models.Card
 	result := r.db.Where("card_number = ?", cardNumber).First(&card)
@@ -43,8 +36,9 @@ func (r *CardRepository) FindByCardNumber(cardNumber string) (*models.Card, erro
 func (r *CardRepository) GetCardsByUserID(userID uint) ([]dto.CardResponse, error) {
 	var result []dto.CardResponse
 	err := r.db.Table("cards").
-		Select("cards.id, cards.card_number, accounts.id as account_id, accounts.balance, accounts.currency, cards.expired_at,  cards.created_at").
+		Select("cards.id, cards.card_number, cards.card_key_id, accounts.id as account_id, accounts.balance, accounts.currency, cards.status, cards.kind, card_products.payment_system, cards.single_use, cards.spending_cap, cards.expired_at,  cards.created_at").
 		Joins("JOIN accounts ON cards.account_id = accounts.id").
+		Joins("LEFT JOIN card_products ON cards.product_id = card_products.id").
 		Where("accounts.user_id = ?", userID).
 		Scan(&result).Error
 	if err != nil {
@@ -53,23 +47,147 @@ func (r *CardRepository) GetCardsByUserID(userID uint) ([]dto.CardResponse, erro
 	return result, nil
 }
 
-func (r *CardRepository) FindByPlainCardNumber(number string, key string) (*models.Card, error) {
+// FindByNumberIndex ищет карту по blind index номера
+func (r *CardRepository) FindByNumberIndex(index string) (*models.Card, error) {
 	var card models.Card
+	result := r.db.Where("card_number_index = ?", index).First(&card)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &card, result.Error
+}
+
+// ExistsByNumberIndex — выдан ли уже номер с таким blind index, в том числе удалённым картам
+func (r *CardRepository) ExistsByNumberIndex(index string) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.Card{}).Where("card_number_index = ?", index).Count(&count).Error
+	return count > 0, err
+}
 
+// BackfillNumberIndex заполняет blind index у карт, выпущенных до его появления. Такие карты зашифрованы pgcrypto.
+// Возвращает количество обновлённых карт.
+func (r *CardRepository) BackfillNumberIndex(pgpKey string, indexKey string) (int64, error) {
 	query := `
-        SELECT * FROM cards 
-        WHERE pgp_sym_decrypt(decode(card_number, 'hex'), ?) = ?
+        UPDATE cards
+        SET card_number_index = encode(hmac(pgp_sym_decrypt(decode(card_number, 'hex'), ?), ?, 'sha256'), 'hex')
+        WHERE card_number_index IS NULL AND card_key_id IS NULL
     `
-	result := r.db.Raw(query, key, number).Scan(&card)
+	result := r.db.Exec(query, pgpKey, indexKey)
+	return result.RowsAffected, result.Error
+}
+
+func (r *CardRepository) FindByID(id uint) (*models.Card, error) {
+	var card models.Card
+	result := r.db.First(&card, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &card, result.Error
+}
+
+// FindByIDAndUserID — карта, привязанная к аккаунту указанного пользователя
+func (r *CardRepository) FindByIDAndUserID(id uint, userID uint) (*models.Card, error) {
+	var card models.Card
+	result := r.db.
+		Joins("JOIN accounts ON cards.account_id = accounts.id").
+		Where("cards.id = ? AND accounts.user_id = ?", id, userID).
+		First(&card)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &card, result.Error
+}
+
+func (r *CardRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*models.Card, error) {
+	var card models.Card
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&card, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	if result.RowsAffected == 0 {
-		return nil, errors.New("card not found")
-	}
 	return &card, nil
 }
 
+// HasSuccessorWithTx — выпущена ли уже карта взамен указанной
+func (r *CardRepository) HasSuccessorWithTx(tx *gorm.DB, id uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.Card{}).Where("reissued_from_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *CardRepository) CreateWithTx(tx *gorm.DB, card *models.Card) error {
+	return tx.Create(card).Error
+}
+
+func (r *CardRepository) UpdateWithTx(tx *gorm.DB, card *models.Card) error {
+	return tx.Save(card).Error
+}
+
+// MarkExpired переводит в статус expired все действующие карты с истёкшим сроком, возвращает их количество
+func (r *CardRepository) MarkExpired(now time.Time) (int64, error) {
+	result := r.db.Model(&models.Card{}).
+		Where("status IN ? AND expired_at < ?", []string{models.CardStatusActive, models.CardStatusBlocked}, now).
+		Updates(map[string]interface{}{
+			"status":            models.CardStatusExpired,
+			"status_changed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// FindNotEncryptedWith — следующая порция карт (включая удалённые) с id больше afterID,
+// номер которых зашифрован не ключом keyID
+func (r *CardRepository) FindNotEncryptedWith(keyID string, afterID uint, limit int) ([]models.Card, error) {
+	var cards []models.Card
+	err := r.db.Unscoped().
+		Where("(card_key_id IS NULL OR card_key_id <> ?) AND id > ?", keyID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&cards).Error
+	return cards, err
+}
+
+// UpdateEncryption сохраняет перешифрованный номер. Возвращает false, если номер успел измениться.
+func (r *CardRepository) UpdateEncryption(card *models.Card, cardNumber string, keyID string) (bool, error) {
+	result := r.db.Unscoped().Model(&models.Card{}).
+		Where("id = ? AND card_number = ?", card.ID, card.CardNumber).
+		Updates(map[string]interface{}{
+			"card_number": cardNumber,
+			"card_key_id": keyID,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindAfterID — следующая порция карт (включая удалённые) с id больше afterID
+func (r *CardRepository) FindAfterID(afterID uint, limit int) ([]models.Card, error) {
+	var cards []models.Card
+	err := r.db.Unscoped().Where("id > ?", afterID).Order("id").Limit(limit).Find(&cards).Error
+	return cards, err
+}
+
+// UpdateNumberIndex сохраняет blind index карты. Возвращает false, если номер успел измениться.
+func (r *CardRepository) UpdateNumberIndex(card *models.Card, index string) (bool, error) {
+	result := r.db.Unscoped().Model(&models.Card{}).
+		Where("id = ? AND card_number = ?", card.ID, card.CardNumber).
+		Update("card_number_index", index)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateLimits сохраняет только лимиты карты, не затрагивая статус и счётчик PIN, которые могли измениться параллельно
+func (r *CardRepository) UpdateLimits(card *models.Card) error {
+	return r.db.Model(&models.Card{}).
+		Where("id = ?", card.ID).
+		Updates(map[string]interface{}{
+			"per_transaction_limit": card.PerTransactionLimit,
+			"daily_limit":           card.DailyLimit,
+			"monthly_limit":         card.MonthlyLimit,
+			"hourly_payments_limit": card.HourlyPaymentsLimit,
+		}).Error
+}
+
+// WithinTransaction — обёртка для выполнения в транзакции
+func (r *CardRepository) WithinTransaction(fn func(*gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
 func (r *CardRepository) Update(card *models.Card) error {
 	return r.db.Save(card).Error
 }
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"BankSystem/internal/models"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+type CardTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewCardTokenRepository(db *gorm.DB) *CardTokenRepository {
+	return &CardTokenRepository{db: db}
+}
+
+func (r *CardTokenRepository) Create(token *models.CardToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *CardTokenRepository) Update(token *models.CardToken) error {
+	return r.db.Save(token).Error
+}
+
+// FindActiveByHash — действующий токен, выданный указанному мерчанту
+func (r *CardTokenRepository) FindActiveByHash(hash string, merchantID uint) (*models.CardToken, error) {
+	var token models.CardToken
+	result := r.db.Where("token_hash = ? AND merchant_id = ? AND status = ?", hash, merchantID, models.CardTokenStatusActive).First(&token)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &token, result.Error
+}
+
+func (r *CardTokenRepository) FindByIDAndCardID(id uint, cardID uint) (*models.CardToken, error) {
+	var token models.CardToken
+	result := r.db.Where("id = ? AND card_id = ?", id, cardID).First(&token)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &token, result.Error
+}
+
+func (r *CardTokenRepository) FindAllByCardID(cardID uint) ([]models.CardToken, error) {
+	var tokens []models.CardToken
+	result := r.db.Where("card_id = ?", cardID).Order("id DESC").Find(&tokens)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tokens, nil
+}
+
+func (r *CardTokenRepository) MarkUsed(id uint, now time.Time) error {
+	return r.db.Model(&models.CardToken{}).Where("id = ?", id).Update("last_used_at", now).Error
+}
+
+// MoveToCardWithTx переносит действующие токены перевыпущенной карты на новую карту
+func (r *CardTokenRepository) MoveToCardWithTx(tx *gorm.DB, fromCardID uint, toCardID uint) error {
+	return tx.Model(&models.CardToken{}).
+		Where("card_id = ? AND status = ?", fromCardID, models.CardTokenStatusActive).
+		Update("card_id", toCardID).Error
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"BankSystem/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type LoginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+func (r *LoginThrottleRepository) WithinTransaction(fn func(*gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// FindOrCreateWithLock — находит счётчик и блокирует строку до конца транзакции, при необходимости создавая её.
+// Параллельные попытки входа для одного ключа проверяются и учитываются по очереди.
+func (r *LoginThrottleRepository) FindOrCreateWithLock(tx *gorm.DB, scope string, key string, now time.Time) (*models.LoginThrottle, error) {
+	err := tx.Exec(`
+        INSERT INTO login_throttles (scope, key, failed_attempts, created_at, updated_at)
+        VALUES (?, ?, 0, ?, ?)
+        ON CONFLICT (scope, key) DO NOTHING
+    `, scope, key, now, now).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var throttle models.LoginThrottle
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("scope = ? AND key = ?", scope, key).
+		First(&throttle).Error
+	return &throttle, err
+}
+
+// ReserveAttemptWithTx — учитывает попытку как неудачную до проверки пароля и возвращает новое значение счётчика.
+// Если последняя неудача была раньше windowStart, счёт начинается заново.
+func (r *LoginThrottleRepository) ReserveAttemptWithTx(tx *gorm.DB, throttle *models.LoginThrottle, now time.Time, windowStart time.Time) (int, error) {
+	attempts := throttle.FailedAttempts + 1
+	if throttle.LastFailedAt == nil || throttle.LastFailedAt.Before(windowStart) {
+		attempts = 1
+	}
+	err := tx.Model(&models.LoginThrottle{}).
+		Where("id = ?", throttle.ID).
+		Updates(map[string]interface{}{
+			"failed_attempts": attempts,
+			"last_failed_at":  now,
+			"updated_at":      now,
+		}).Error
+	return attempts, err
+}
+
+// ReleaseAttempt — возвращает зарезервированную попытку, если пароль оказался верным
+func (r *LoginThrottleRepository) ReleaseAttempt(scope string, key string) error {
+	return r.db.Model(&models.LoginThrottle{}).
+		Where("scope = ? AND key = ? AND failed_attempts > 0", scope, key).
+		Updates(map[string]interface{}{
+			"failed_attempts": gorm.Expr("failed_attempts - 1"),
+			"updated_at":      time.Now().UTC(),
+		}).Error
+}
+
+// Lock — блокирует вход до указанного момента и обнуляет счётчик
+func (r *LoginThrottleRepository) Lock(scope string, key string, until time.Time) error {
+	return r.db.Model(&models.LoginThrottle{}).
+		Where("scope = ? AND key = ?", scope, key).
+		Updates(map[string]interface{}{
+			"failed_attempts": 0,
+			"locked_until":    until,
+			"updated_at":      time.Now().UTC(),
+		}).Error
+}
+
+// Reset — снимает блокировку и обнуляет счётчик
+func (r *LoginThrottleRepository) Reset(scope string, key string) error {
+	return r.db.Model(&models.LoginThrottle{}).
+		Where("scope = ? AND key = ?", scope, key).
+		Updates(map[string]interface{}{
+			"failed_attempts": 0,
+			"last_failed_at":  nil,
+			"locked_until":    nil,
+			"updated_at":      time.Now().UTC(),
+		}).Error
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"BankSystem/internal/models"
+	"errors"
+	"gorm.io/gorm"
+)
+
+type MerchantRepository struct {
+	db *gorm.DB
+}
+
+func NewMerchantRepository(db *gorm.DB) *MerchantRepository {
+	return &MerchantRepository{db: db}
+}
+
+func (r *MerchantRepository) Create(merchant *models.Merchant) error {
+	return r.db.Create(merchant).Error
+}
+
+func (r *MerchantRepository) FindByID(id uint) (*models.Merchant, error) {
+	var merchant models.Merchant
+	result := r.db.First(&merchant, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &merchant, result.Error
+}
+
+func (r *MerchantRepository) FindByIDAndOwnerID(id uint, ownerID uint) (*models.Merchant, error) {
+	var merchant models.Merchant
+	result := r.db.Where("id = ? AND owner_user_id = ?", id, ownerID).First(&merchant)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &merchant, result.Error
+}
+
+func (r *MerchantRepository) FindAllByOwnerID(ownerID uint) ([]models.Merchant, error) {
+	var merchants []models.Merchant
+	result := r.db.Where("owner_user_id = ?", ownerID).Order("id").Find(&merchants)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return merchants, nil
+}
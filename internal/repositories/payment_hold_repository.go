@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"BankSystem/internal/models"
+	"errors"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// reservedStatuses — статусы резервов, которые удерживают сумму на аккаунте
+var reservedStatuses = []string{models.HoldStatusChallenged, models.HoldStatusAuthorized}
+
+type PaymentHoldRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentHoldRepository(db *gorm.DB) *PaymentHoldRepository {
+	return &PaymentHoldRepository{db: db}
+}
+
+func (r *PaymentHoldRepository) FindByID(id uint) (*models.PaymentHold, error) {
+	var hold models.PaymentHold
+	result := r.db.First(&hold, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &hold, result.Error
+}
+
+func (r *PaymentHoldRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*models.PaymentHold, error) {
+	var hold models.PaymentHold
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hold, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &hold, nil
+}
+
+func (r *PaymentHoldRepository) CreateWithTx(tx *gorm.DB, hold *models.PaymentHold) error {
+	return tx.Create(hold).Error
+}
+
+func (r *PaymentHoldRepository) UpdateWithTx(tx *gorm.DB, hold *models.PaymentHold) error {
+	return tx.Save(hold).Error
+}
+
+// FindExpiredIDs — неподтверждённые или не прошедшие проверку кода резервы, срок которых истёк к моменту now
+func (r *PaymentHoldRepository) FindExpiredIDs(now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.PaymentHold{}).
+		Where("status IN ? AND expires_at < ?", reservedStatuses, now).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// SumAuthorized — сумма ещё не списанных резервов по карте, созданных начиная с since
+func (r *PaymentHoldRepository) SumAuthorized(cardID uint, since time.Time) (decimal.Decimal, error) {
+	var sum decimal.Decimal
+	err := r.db.Model(&models.PaymentHold{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("card_id = ? AND status IN ? AND created_at >= ?", cardID, reservedStatuses, since).
+		Scan(&sum).Error
+	return sum, err
+}
+
+// CountAuthorized — количество ещё не списанных резервов по карте, созданных начиная с since
+func (r *PaymentHoldRepository) CountAuthorized(cardID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PaymentHold{}).
+		Where("card_id = ? AND status IN ? AND created_at >= ?", cardID, reservedStatuses, since).
+		Count(&count).Error
+	return count, err
+}
+
+// FindByMerchantID — оплаты мерчанта, новые первыми
+func (r *PaymentHoldRepository) FindByMerchantID(merchantID uint, limit int, offset int) ([]models.PaymentHold, error) {
+	var holds []models.PaymentHold
+	result := r.db.
+		Where("merchant_id = ?", merchantID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&holds)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return holds, nil
+}
+
+// WithinTransaction — обёртка для выполнения в транзакции
+func (r *PaymentHoldRepository) WithinTransaction(fn func(*gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"BankSystem/internal/models"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type StandingOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewStandingOrderRepository(db *gorm.DB) *StandingOrderRepository {
+	return &StandingOrderRepository{db: db}
+}
+
+func (r *StandingOrderRepository) Create(order *models.StandingOrder) error {
+	return r.db.Create(order).Error
+}
+
+func (r *StandingOrderRepository) FindByIDAndUserID(id uint, userID uint) (*models.StandingOrder, error) {
+	var order models.StandingOrder
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).First(&order)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &order, result.Error
+}
+
+func (r *StandingOrderRepository) FindAllByUserID(userID uint) ([]models.StandingOrder, error) {
+	var orders []models.StandingOrder
+	result := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&orders)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return orders, nil
+}
+
+func (r *StandingOrderRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*models.StandingOrder, error) {
+	var order models.StandingOrder
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &order, nil
+}
+
+func (r *StandingOrderRepository) UpdateWithTx(tx *gorm.DB, order *models.StandingOrder) error {
+	return tx.Save(order).Error
+}
+
+// FindDueIDs — активные поручения, плановый запуск или повтор которых наступил к now
+func (r *StandingOrderRepository) FindDueIDs(now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.StandingOrder{}).
+		Where("status = ? AND COALESCE(retry_at, next_run_at) <= ?", models.StandingOrderStatusActive, now).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *StandingOrderRepository) CreateExecutionWithTx(tx *gorm.DB, execution *models.StandingOrderExecution) error {
+	return tx.Create(execution).Error
+}
+
+// FindExecutions — история запусков поручения, новые первыми
+func (r *StandingOrderRepository) FindExecutions(orderID uint, limit int, offset int) ([]models.StandingOrderExecution, error) {
+	var executions []models.StandingOrderExecution
+	result := r.db.
+		Where("standing_order_id = ?", orderID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&executions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return executions, nil
+}
+
+// WithinTransaction — обёртка для выполнения в транзакции
+func (r *StandingOrderRepository) WithinTransaction(fn func(*gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
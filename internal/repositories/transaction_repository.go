@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"BankSystem/internal/models"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"time"
+)
+
+type TransactionRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+// FindByAccountID — операции, в которых аккаунт был отправителем или получателем, новые первыми
+func (r *TransactionRepository) FindByAccountID(accountID uint, limit int, offset int) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	result := r.db.
+		Where("from_account_id = ? OR to_account_id = ?", accountID, accountID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&transactions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return transactions, nil
+}
+
+// FindByCardID — операции по карте, новые первыми
+func (r *TransactionRepository) FindByCardID(cardID uint, limit int, offset int) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	result := r.db.
+		Where("card_id = ?", cardID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&transactions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return transactions, nil
+}
+
+// cardSpendingTypes — операции, которые расходуют лимиты карты: оплаты, переводы с карты на карту и снятие наличных
+var cardSpendingTypes = []string{"payment", "transfer", "withdrawal"}
+
+// SumCardPayments — сумма оплат, переводов и снятий наличных по карте начиная с since
+func (r *TransactionRepository) SumCardPayments(cardID uint, since time.Time) (decimal.Decimal, error) {
+	var sum decimal.Decimal
+	err := r.db.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("card_id = ? AND transaction_type IN ? AND created_at >= ?", cardID, cardSpendingTypes, since).
+		Scan(&sum).Error
+	return sum, err
+}
+
+// SumCardWithdrawals — сумма снятий наличных по карте начиная с since
+func (r *TransactionRepository) SumCardWithdrawals(cardID uint, since time.Time) (decimal.Decimal, error) {
+	var sum decimal.Decimal
+	err := r.db.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("card_id = ? AND transaction_type = ? AND created_at >= ?", cardID, "withdrawal", since).
+		Scan(&sum).Error
+	return sum, err
+}
+
+// CountCardPayments — количество оплат, переводов и снятий наличных по карте начиная с since
+func (r *TransactionRepository) CountCardPayments(cardID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Transaction{}).
+		Where("card_id = ? AND transaction_type IN ? AND created_at >= ?", cardID, cardSpendingTypes, since).
+		Count(&count).Error
+	return count, err
+}
@@ -6,6 +6,7 @@ import (
 	"BankSystem/internal/models"
 	"errors"
 	"gorm.io/gorm"
+	"time"
 )
 
 type UserRepository struct {
@@ -30,6 +31,15 @@ func (r *UserRepository) FindByID(id uint) (*models.User, error) {
 	return &user, result.Error
 }
 
+func (r *UserRepository) FindAll(limit int, offset int) ([]models.User, error) {
+	var users []models.User
+	result := r.db.Order("id").Limit(limit).Offset(offset).Find(&users)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return users, nil
+}
+
 func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	var user models.User
 	result := r.db.Where("email = ?", email).First(&user)
@@ -48,10 +58,44 @@ func (r *UserRepository) FindByUserName(username string) (*models.User, error) {
 	return &user, result.Error
 }
 
+func (r *UserRepository) UpdateDefaultAccount(userID uint, accountID uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("default_account_id", accountID).Error
+}
+
+func (r *UserRepository) UpdateRole(userID uint, role string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("role", role).Error
+}
+
 func (r *UserRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// UpdatePasswordWithTx — смена пароля в рамках транзакции с фиксацией момента смены
+func (r *UserRepository) UpdatePasswordWithTx(tx *gorm.DB, userID uint, passwordHash string, changedAt time.Time) error {
+	return tx.Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"password":            passwordHash,
+			"password_changed_at": changedAt,
+		}).Error
+}
+
+// TouchVerificationSent — фиксирует отправку письма подтверждения, если с прошлой отправки прошло достаточно времени.
+// Возвращает false, если письмо отправлялось позже sentBefore.
+func (r *UserRepository) TouchVerificationSent(userID uint, sentAt time.Time, sentBefore time.Time) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND (verification_sent_at IS NULL OR verification_sent_at < ?)", userID, sentBefore).
+		Update("verification_sent_at", sentAt)
+	return result.RowsAffected == 1, result.Error
+}
+
+// MarkEmailVerified — отмечает email пользователя подтверждённым
+func (r *UserRepository) MarkEmailVerified(userID uint, verifiedAt time.Time) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", userID).
+		Update("email_verified_at", verifiedAt).Error
+}
+
 func (r *UserRepository) Delete(id uint) error {
 	return r.db.Delete(&models.User{}, id).Error
 }
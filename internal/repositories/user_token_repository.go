@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"BankSystem/internal/models"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+type UserTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+func (r *UserTokenRepository) Create(token *models.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindActiveByHash — поиск неиспользованного и не истёкшего токена по хешу
+func (r *UserTokenRepository) FindActiveByHash(hash string, purpose string) (*models.UserToken, error) {
+	var token models.UserToken
+	result := r.db.
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, time.Now()).
+		First(&token)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &token, result.Error
+}
+
+// InvalidateForUser — помечает все активные токены пользователя с указанным назначением использованными
+func (r *UserTokenRepository) InvalidateForUser(userID uint, purpose string) error {
+	return r.InvalidateForUserWithTx(r.db, userID, purpose)
+}
+
+func (r *UserTokenRepository) InvalidateForUserWithTx(tx *gorm.DB, userID uint, purpose string) error {
+	return tx.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
+
+// MarkUsedWithTx — атомарно помечает токен использованным, возвращает false, если его уже использовали
+func (r *UserTokenRepository) MarkUsedWithTx(tx *gorm.DB, id uint) (bool, error) {
+	result := tx.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// WithinTransaction — обёртка для выполнения в транзакции
+func (r *UserTokenRepository) WithinTransaction(fn func(*gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
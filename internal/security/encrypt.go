@@ -3,21 +3,78 @@ package security
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"encoding/base64"
+	"errors"
 )
 
-var encryptionKey = []byte("32_byte_key_here_32_byte_key_here")
+var (
+	ErrUnknownKey        = errors.New("encryption key is not in the keyring")
+	ErrInvalidKey        = errors.New("encryption key must be 32 bytes")
+	ErrInvalidCiphertext = errors.New("ciphertext is malformed or was tampered with")
+)
 
-func Encrypt(text string) (string, error) {
-	block, err := aes.NewCipher(encryptionKey)
-	if err != nil {
-		return "", err
+// Keyring — набор ключей AES-256-GCM. Шифрование выполняется активным ключом, расшифровка — ключом,
+// идентификатор которого сохранён вместе с шифротекстом. Старые ключи остаются в наборе до перешифрования данных.
+type Keyring struct {
+	activeID string
+	keys     map[string]cipher.AEAD
+}
+
+// NewKeyring создаёт набор из ключей id → 32 байта; activeID должен присутствовать в наборе
+func NewKeyring(keys map[string][]byte, activeID string) (*Keyring, error) {
+	keyring := &Keyring{activeID: activeID, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if len(key) != 32 {
+			return nil, ErrInvalidKey
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[id] = aead
+	}
+	if _, ok := keyring.keys[activeID]; !ok {
+		return nil, ErrUnknownKey
+	}
+	return keyring, nil
+}
+
+// ActiveKeyID — идентификатор ключа, которым шифруются новые данные
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// Encrypt шифрует текст активным ключом со случайным nonce. Возвращает base64(nonce || ciphertext) и идентификатор ключа.
+// Идентификатор ключа участвует в аутентификации, поэтому подменить его в БД незаметно нельзя.
+func (k *Keyring) Encrypt(text string) (string, string, error) {
+	aead := k.keys[k.activeID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(text), []byte(k.activeID))
+	return base64.StdEncoding.EncodeToString(sealed), k.activeID, nil
+}
+
+// Decrypt расшифровывает результат Encrypt ключом keyID
+func (k *Keyring) Decrypt(ciphertext string, keyID string) (string, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", ErrInvalidCiphertext
 	}
-	gcm, err := cipher.NewGCM(block)
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, []byte(keyID))
 	if err != nil {
-		return "", err
+		return "", ErrInvalidCiphertext
 	}
-	nonce := []byte("unique_nonce123") // В реальности генерируйте уникальный nonce
-	encrypted := gcm.Seal(nil, nonce, []byte(text), nil)
-	return base64.StdEncoding.EncodeToString(encrypted), nil
+	return string(plain), nil
 }
@@ -0,0 +1,36 @@
+package security
+
+import "BankSystem/internal/models"
+
+const (
+	PermissionUsersRead      = "users:read"
+	PermissionAccountsRead   = "accounts:read"
+	PermissionAccountsManage = "accounts:manage"
+	PermissionAuditRead      = "audit:read"
+)
+
+// rolePermissions — права, которые даёт каждая роль. У клиента прав на административные операции нет.
+var rolePermissions = map[string][]string{
+	models.RoleCustomer: {},
+	models.RoleOperator: {
+		PermissionUsersRead,
+		PermissionAccountsRead,
+		PermissionAccountsManage,
+	},
+	models.RoleAdmin: {
+		PermissionUsersRead,
+		PermissionAccountsRead,
+		PermissionAccountsManage,
+		PermissionAuditRead,
+	},
+}
+
+// HasPermission проверяет, есть ли у роли указанное право
+func HasPermission(role string, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
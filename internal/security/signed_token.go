@@ -0,0 +1,43 @@
+package security
+
+import (
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+)
+
+var ErrInvalidSignedToken = errors.New("token is invalid or expired")
+
+// PurposeClaims — claims подписанных ссылок из писем, purpose не даёт использовать токен в другом сценарии
+type PurposeClaims struct {
+	jwt.RegisteredClaims
+	Purpose string `json:"purpose"`
+	UserID  uint   `json:"user_id"`
+}
+
+// SignPurposeToken подписывает токен для ссылки с указанным назначением
+func SignPurposeToken(secret []byte, purpose string, userID uint, email string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &PurposeClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   email,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Purpose: purpose,
+		UserID:  userID,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// ParsePurposeToken проверяет подпись, срок действия и назначение токена
+func ParsePurposeToken(secret []byte, purpose string, tokenString string) (*PurposeClaims, error) {
+	claims := &PurposeClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid || claims.Purpose != purpose {
+		return nil, ErrInvalidSignedToken
+	}
+	return claims, nil
+}
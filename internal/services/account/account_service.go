@@ -4,6 +4,7 @@ import (
 	"BankSystem/internal/models"
 	"BankSystem/internal/repositories"
 	"errors"
+	"fmt"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -11,6 +12,22 @@ import (
 	"time"
 )
 
+var (
+	ErrAccountNotFound         = errors.New("account not found")
+	ErrInsufficientFunds       = errors.New("insufficient funds")
+	ErrAccountFrozen           = errors.New("account is frozen, debit operations are not allowed")
+	ErrAccountBlocked          = errors.New("account is blocked")
+	ErrAccountClosed           = errors.New("account is closed")
+	ErrInvalidStatusTransition = errors.New("account status transition is not allowed")
+	ErrNonZeroBalance          = errors.New("account balance must be zero or transferred to another account before closing")
+	ErrInvalidClosureTarget    = errors.New("remaining balance can only be transferred to another account of the same customer in the same currency that accepts credits")
+	ErrSameAccount             = errors.New("sender and recipient accounts must be different")
+	ErrActiveHolds             = errors.New("account has uncaptured card payments, void them or wait for expiry before closing")
+	// ErrSenderAccountNotFound и ErrRecipientAccountNotFound уточняют ErrAccountNotFound для переводов
+	ErrSenderAccountNotFound    = fmt.Errorf("sender %w", ErrAccountNotFound)
+	ErrRecipientAccountNotFound = fmt.Errorf("recipient %w", ErrAccountNotFound)
+)
+
 type AccountService struct {
 	accountRepo *repositories.AccountRepository
 	userRepo    *repositories.UserRepository
@@ -24,49 +41,69 @@ func NewAccountService(repo *repositories.AccountRepository, log *logrus.Logger)
 	}
 }
 
-func (s *AccountService) CreateAccount(userID uint, balance decimal.Decimal) error {
+func (s *AccountService) CreateAccount(userID uint, balance decimal.Decimal) (*models.Account, error) {
 	account := &models.Account{
 		UserID:   userID,
 		Balance:  balance,
 		Currency: "RUB",
+		Status:   models.AccountStatusActive,
 	}
 	account.DeletedAt = gorm.DeletedAt{Valid: false, Time: time.Time{}}
 	logrus.Info("created new account for user" + strconv.Itoa(int(userID)))
-	return s.accountRepo.Create(account)
+	if err := s.accountRepo.Create(account); err != nil {
+		return nil, err
+	}
+	return account, nil
 }
 
 func (s *AccountService) Deposit(id uint, userID uint, amount decimal.Decimal) (decimal.Decimal, error) {
-	account, err := s.accountRepo.FindByIdAndUserID(id, userID)
-	if err != nil || account == nil {
-		return decimal.Zero, errors.New("account not found")
-	}
+	var balance decimal.Decimal
+	err := s.accountRepo.WithinTransaction(func(tx *gorm.DB) error {
+		account, err := s.accountRepo.FindByIDWithLock(tx, id)
+		if err != nil || account.UserID != userID {
+			return ErrAccountNotFound
+		}
+		if err := CheckCredit(account); err != nil {
+			return err
+		}
 
-	account.Balance = account.Balance.Add(amount)
-	if err := s.accountRepo.Update(account); err != nil {
+		account.Balance = account.Balance.Add(amount)
+		balance = account.Balance
+		return s.accountRepo.UpdateWithTx(tx, account)
+	})
+	if err != nil {
 		return decimal.Zero, err
 	}
 
 	logrus.Info("user " + strconv.Itoa(int(userID)) + " has been deposited successfully")
-	return account.Balance, nil
+	return balance, nil
 }
 
 func (s *AccountService) Withdraw(id uint, userID uint, amount decimal.Decimal) (decimal.Decimal, error) {
-	account, err := s.accountRepo.FindByIdAndUserID(id, userID)
-	if err != nil || account == nil {
-		return decimal.Zero, errors.New("account not found")
-	}
+	var balance decimal.Decimal
+	err := s.accountRepo.WithinTransaction(func(tx *gorm.DB) error {
+		account, err := s.accountRepo.FindByIDWithLock(tx, id)
+		if err != nil || account.UserID != userID {
+			return ErrAccountNotFound
+		}
+		if err := CheckDebit(account); err != nil {
+			return err
+		}
 
-	if account.Balance.LessThan(amount) {
-		return decimal.Zero, errors.New("insufficient funds")
-	}
+		if account.AvailableBalance().LessThan(amount) {
+			return ErrInsufficientFunds
+		}
 
-	account.Balance = account.Balance.Sub(amount)
-	if err := s.accountRepo.Update(account); err != nil {
+		account.Balance = account.Balance.Sub(amount)
+		balance = account.Balance
+		return s.accountRepo.UpdateWithTx(tx, account)
+	})
+	if err != nil {
 		return decimal.Zero, err
 	}
 
 	logrus.Info("user " + strconv.Itoa(int(userID)) + " has been withdraw successfully")
-	return account.Balance, nil
+	return balance, nil
 }
 
 func (s *AccountService) IsAccountExists(userID uint) (bool, error) {
@@ -104,45 +141,227 @@ func (s *AccountService) GetAccountsByUserID(userID uint) ([]*models.Account, er
 }
 
 func (s *AccountService) Transfer(userID uint, fromAccID uint, toAccID uint, amount decimal.Decimal) error {
+	if fromAccID == toAccID {
+		return ErrSameAccount
+	}
+
 	account, err := s.accountRepo.FindByIdAndUserID(fromAccID, userID)
 	if err != nil || account == nil {
-		return errors.New("account not found")
+		return ErrAccountNotFound
 	}
 
 	return s.accountRepo.WithinTransaction(func(tx *gorm.DB) error {
-		fromAccount, err := s.accountRepo.FindByIDWithLock(fromAccID)
+		_, _, err := s.TransferWithTx(tx, fromAccID, toAccID, amount, TransferDetails{})
+		return err
+	})
+}
+
+// TransferDetails — необязательные сведения о переводе, сохраняемые в операции
+type TransferDetails struct {
+	// CardID — карта отправителя, если перевод выполнен по номеру карты
+	CardID      *uint
+	Description *string
+}
+
+// TransferWithTx блокирует оба аккаунта и переводит средства в транзакции tx, возвращает аккаунты после перевода
+func (s *AccountService) TransferWithTx(tx *gorm.DB, fromAccID uint, toAccID uint, amount decimal.Decimal, details TransferDetails) (*models.Account, *models.Account, error) {
+	if fromAccID == toAccID {
+		return nil, nil, ErrSameAccount
+	}
+	fromAccount, toAccount, err := s.LockPair(tx, fromAccID, toAccID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.moveFunds(tx, fromAccount, toAccount, amount, details); err != nil {
+		return nil, nil, err
+	}
+	return fromAccount, toAccount, nil
+}
+
+// ChangeStatus — смена статуса аккаунта оператором, возвращает прежний статус
+func (s *AccountService) ChangeStatus(id uint, status string) (string, error) {
+	var previous string
+	err := s.accountRepo.WithinTransaction(func(tx *gorm.DB) error {
+		account, err := s.accountRepo.FindByIDWithLock(tx, id)
 		if err != nil {
-			return errors.New("sender account not found")
+			return ErrAccountNotFound
+		}
+		if !account.CanTransitTo(status) {
+			return ErrInvalidStatusTransition
 		}
 
-		toAccount, err := s.accountRepo.FindByIDWithLock(toAccID)
+		previous = account.Status
+		account.Status = status
+		return s.accountRepo.UpdateWithTx(tx, account)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	logrus.Info("account " + strconv.Itoa(int(id)) + " status changed from " + previous + " to " + status)
+	return previous, nil
+}
+
+// Close закрывает аккаунт. Ненулевой остаток переводится на transferToID — другой аккаунт того же клиента,
+// без него закрыть можно только пустой аккаунт.
+// Возвращает прежний статус и переведённую сумму.
+func (s *AccountService) Close(id uint, transferToID *uint) (string, decimal.Decimal, error) {
+	var previous string
+	transferred := decimal.Zero
+
+	err := s.accountRepo.WithinTransaction(func(tx *gorm.DB) error {
+		var account *models.Account
+		var target *models.Account
+		var err error
+
+		if transferToID != nil {
+			if *transferToID == id {
+				return ErrInvalidClosureTarget
+			}
+			account, target, err = s.LockPair(tx, id, *transferToID)
+		} else {
+			account, err = s.accountRepo.FindByIDWithLock(tx, id)
+			if err != nil {
+				err = ErrAccountNotFound
+			}
+		}
 		if err != nil {
-			return errors.New("recipient account not found")
+			return err
 		}
 
-		if fromAccount.Balance.LessThan(amount) {
-			return errors.New("insufficient funds")
+		if account.Status == models.AccountStatusClosed {
+			return ErrAccountClosed
+		}
+		if account.HoldAmount.IsPositive() {
+			return ErrActiveHolds
 		}
 
-		fromAccount.Balance = fromAccount.Balance.Sub(amount)
-		toAccount.Balance = toAccount.Balance.Add(amount)
+		if account.Balance.IsPositive() {
+			if target == nil {
+				return ErrNonZeroBalance
+			}
+			if target.UserID != account.UserID || !target.CanCredit() || target.Currency != account.Currency {
+				return ErrInvalidClosureTarget
+			}
 
-		if err := s.accountRepo.UpdateWithTx(tx, fromAccount); err != nil {
-			return err
+			transferred = account.Balance
+			if err := s.applyTransfer(tx, account, target, transferred, TransferDetails{}); err != nil {
+				return err
+			}
 		}
 
-		if err := s.accountRepo.UpdateWithTx(tx, toAccount); err != nil {
-			return err
-		}
+		closedAt := time.Now().UTC()
+		previous = account.Status
+		account.Status = models.AccountStatusClosed
+		account.ClosedAt = &closedAt
+		return s.accountRepo.UpdateWithTx(tx, account)
+	})
+	if err != nil {
+		return "", decimal.Zero, err
+	}
 
-		tx.Create(&models.Transaction{
-			FromAccountID:   fromAccID,
-			ToAccountID:     toAccID,
-			Amount:          amount,
-			TransactionType: "transfer",
-			Currency:        "RUB",
-		})
+	logrus.Info("account " + strconv.Itoa(int(id)) + " has been closed, transferred " + transferred.StringFixed(2))
+	return previous, transferred, nil
+}
+
+// LockPair блокирует в транзакции два аккаунта в порядке возрастания ID, чтобы встречные переводы не взаимоблокировались
+func (s *AccountService) LockPair(tx *gorm.DB, fromAccID uint, toAccID uint) (*models.Account, *models.Account, error) {
+	firstID, secondID := fromAccID, toAccID
+	if secondID < firstID {
+		firstID, secondID = secondID, firstID
+	}
+
+	first, err := s.accountRepo.FindByIDWithLock(tx, firstID)
+	if err != nil {
+		return nil, nil, s.lockError(firstID, fromAccID)
+	}
+	second, err := s.accountRepo.FindByIDWithLock(tx, secondID)
+	if err != nil {
+		return nil, nil, s.lockError(secondID, fromAccID)
+	}
+
+	if first.ID == fromAccID {
+		return first, second, nil
+	}
+	return second, first, nil
+}
+
+func (s *AccountService) lockError(id uint, fromAccID uint) error {
+	if id == fromAccID {
+		return ErrSenderAccountNotFound
+	}
+	return ErrRecipientAccountNotFound
+}
+
+// moveFunds переводит средства между заблокированными в tx аккаунтами с проверкой статусов и баланса
+func (s *AccountService) moveFunds(tx *gorm.DB, fromAccount *models.Account, toAccount *models.Account, amount decimal.Decimal, details TransferDetails) error {
+	if err := CheckDebit(fromAccount); err != nil {
+		return err
+	}
+	if err := CheckCredit(toAccount); err != nil {
+		return err
+	}
 
+	if fromAccount.AvailableBalance().LessThan(amount) {
+		return ErrInsufficientFunds
+	}
+
+	return s.applyTransfer(tx, fromAccount, toAccount, amount, details)
+}
+
+// applyTransfer списывает и зачисляет сумму без проверки статусов и записывает операцию
+func (s *AccountService) applyTransfer(tx *gorm.DB, fromAccount *models.Account, toAccount *models.Account, amount decimal.Decimal, details TransferDetails) error {
+	fromAccount.Balance = fromAccount.Balance.Sub(amount)
+	toAccount.Balance = toAccount.Balance.Add(amount)
+
+	if err := s.accountRepo.UpdateWithTx(tx, fromAccount); err != nil {
+		return err
+	}
+
+	if err := s.accountRepo.UpdateWithTx(tx, toAccount); err != nil {
+		return err
+	}
+
+	return tx.Create(&models.Transaction{
+		FromAccountID:   fromAccount.ID,
+		ToAccountID:     toAccount.ID,
+		Amount:          amount,
+		TransactionType: "transfer",
+		Currency:        "RUB",
+		CardID:          details.CardID,
+		Description:     details.Description,
+	}).Error
+}
+
+// CheckDebit — разрешено ли списание с аккаунта в его текущем статусе
+func CheckDebit(account *models.Account) error {
+	if account.CanDebit() {
 		return nil
-	})
+	}
+	return statusError(account)
+}
+
+// CheckCredit — разрешено ли зачисление на аккаунт в его текущем статусе
+func CheckCredit(account *models.Account) error {
+	if account.CanCredit() {
+		return nil
+	}
+	return statusError(account)
+}
+
+func statusError(account *models.Account) error {
+	switch account.Status {
+	case models.AccountStatusFrozen:
+		return ErrAccountFrozen
+	case models.AccountStatusBlocked:
+		return ErrAccountBlocked
+	case models.AccountStatusClosed:
+		return ErrAccountClosed
+	}
+	return ErrAccountNotFound
+}
+
+// IsStatusError — ошибка вызвана статусом аккаунта (заморожен, заблокирован, закрыт)
+func IsStatusError(err error) bool {
+	return errors.Is(err, ErrAccountFrozen) || errors.Is(err, ErrAccountBlocked) || errors.Is(err, ErrAccountClosed)
 }
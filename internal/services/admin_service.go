@@ -0,0 +1,214 @@
+package services
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/repositories"
+	"BankSystem/internal/services/account"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"strconv"
+)
+
+var ErrAccountNotFound = errors.New("account not found")
+
+// AdminService — операции операторов и администраторов над данными любых пользователей.
+// Каждое обращение фиксируется в журнале аудита.
+type AdminService struct {
+	userRepo        *repositories.UserRepository
+	accountRepo     *repositories.AccountRepository
+	transactionRepo *repositories.TransactionRepository
+	accountService  *account.AccountService
+	auditService    *AuditService
+	log             *logrus.Logger
+}
+
+func NewAdminService(
+	userRepo *repositories.UserRepository,
+	accountRepo *repositories.AccountRepository,
+	transactionRepo *repositories.TransactionRepository,
+	accountService *account.AccountService,
+	auditService *AuditService,
+	log *logrus.Logger) *AdminService {
+	return &AdminService{
+		userRepo:        userRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		accountService:  accountService,
+		auditService:    auditService,
+		log:             log,
+	}
+}
+
+func (s *AdminService) ListUsers(actx AuditContext, limit int, offset int) ([]dto.AdminUserResponse, error) {
+	users, err := s.userRepo.FindAll(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionAdminUsersList,
+		EntityType:   models.AuditEntityUser,
+		Details:      map[string]interface{}{"limit": limit, "offset": offset, "count": len(users)},
+	}); err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.AdminUserResponse, 0, len(users))
+	for i := range users {
+		result = append(result, dto.NewAdminUserResponse(&users[i]))
+	}
+	return result, nil
+}
+
+func (s *AdminService) GetAccount(actx AuditContext, accountID uint) (*dto.AdminAccountResponse, error) {
+	acc, err := s.accountRepo.FindByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil {
+		return nil, ErrAccountNotFound
+	}
+
+	owner, err := s.userRepo.FindByID(acc.UserID)
+	if err != nil || owner == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.auditService.Record(AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionAdminAccountView,
+		EntityType:   models.AuditEntityAccount,
+		EntityID:     strconv.Itoa(int(accountID)),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &dto.AdminAccountResponse{
+		Account: acc,
+		Owner:   dto.NewAdminUserResponse(owner),
+	}, nil
+}
+
+func (s *AdminService) GetAccountTransactions(actx AuditContext, accountID uint, limit int, offset int) ([]models.Transaction, error) {
+	acc, err := s.accountRepo.FindByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil {
+		return nil, ErrAccountNotFound
+	}
+
+	transactions, err := s.transactionRepo.FindByAccountID(accountID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionAdminTransactionsView,
+		EntityType:   models.AuditEntityAccount,
+		EntityID:     strconv.Itoa(int(accountID)),
+		Details:      map[string]interface{}{"limit": limit, "offset": offset, "count": len(transactions)},
+	}); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// ChangeAccountStatus — заморозка, блокировка или разблокировка аккаунта оператором
+func (s *AdminService) ChangeAccountStatus(actx AuditContext, accountID uint, status string) error {
+	previous, err := s.accountService.ChangeStatus(accountID, status)
+	if err != nil {
+		return err
+	}
+
+	s.auditService.TryRecord(AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionAccountStatusChange,
+		EntityType:   models.AuditEntityAccount,
+		EntityID:     strconv.Itoa(int(accountID)),
+		Details:      map[string]interface{}{"from": previous, "to": status},
+	})
+	return nil
+}
+
+// CloseAccount закрывает аккаунт, при необходимости переводя остаток на transferToID
+func (s *AdminService) CloseAccount(actx AuditContext, accountID uint, transferToID *uint) (decimal.Decimal, error) {
+	previous, transferred, err := s.accountService.Close(accountID, transferToID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	details := map[string]interface{}{
+		"from":        previous,
+		"to":          models.AccountStatusClosed,
+		"transferred": transferred.StringFixed(2),
+	}
+	if transferToID != nil {
+		details["transfer_to_account_id"] = *transferToID
+	}
+	s.auditService.TryRecord(AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionAccountClose,
+		EntityType:   models.AuditEntityAccount,
+		EntityID:     strconv.Itoa(int(accountID)),
+		Details:      details,
+	})
+	return transferred, nil
+}
+
+// FindAuditLogs — выборка журнала аудита. Просмотр журнала сам фиксируется в журнале.
+func (s *AdminService) FindAuditLogs(actx AuditContext, filter repositories.AuditLogFilter, limit int, offset int) ([]dto.AuditLogResponse, error) {
+	entries, err := s.auditService.Find(filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{"limit": limit, "offset": offset, "count": len(entries)}
+	if filter.ActorID != nil {
+		details["actor_id"] = *filter.ActorID
+	}
+	if filter.Action != "" {
+		details["action"] = filter.Action
+	}
+	if filter.EntityType != "" {
+		details["entity_type"] = filter.EntityType
+	}
+	if filter.EntityID != "" {
+		details["entity_id"] = filter.EntityID
+	}
+	if err := s.auditService.Record(AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionAdminAuditView,
+		EntityType:   models.AuditEntityAuditLog,
+		Details:      details,
+	}); err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.AuditLogResponse, 0, len(entries))
+	for i := range entries {
+		result = append(result, dto.NewAuditLogResponse(&entries[i]))
+	}
+	return result, nil
+}
+
+// VerifyAuditChain пересчитывает хеши всего журнала и сообщает о первой испорченной записи
+func (s *AdminService) VerifyAuditChain(actx AuditContext) (*AuditChainReport, error) {
+	report, err := s.auditService.VerifyChain()
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.TryRecord(AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionAdminAuditVerify,
+		EntityType:   models.AuditEntityAuditLog,
+		Details:      map[string]interface{}{"valid": report.Valid, "checked": report.Checked},
+	})
+	return report, nil
+}
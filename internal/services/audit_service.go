@@ -0,0 +1,245 @@
+package services
+
+import (
+	"BankSystem/internal/models"
+	"BankSystem/internal/repositories"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditContext — кто и откуда выполняет операцию
+type AuditContext struct {
+	ActorID   *uint
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// AuditEntry — данные для записи в журнал аудита. Before и After — состояние объекта до и после операции.
+type AuditEntry struct {
+	AuditContext
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     interface{}
+	After      interface{}
+	Details    map[string]interface{}
+}
+
+// AuditChainReport — результат проверки целостности цепочки хешей
+type AuditChainReport struct {
+	Valid      bool  `json:"valid"`
+	Checked    int   `json:"checked"`
+	Legacy     int   `json:"legacy"`
+	BrokenAtID *uint `json:"broken_at_id,omitempty"`
+}
+
+type AuditService struct {
+	auditRepo *repositories.AuditRepository
+	log       *logrus.Logger
+}
+
+func NewAuditService(auditRepo *repositories.AuditRepository, log *logrus.Logger) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		log:       log,
+	}
+}
+
+// Record добавляет запись в журнал. Ошибка записи логируется и возвращается вызывающему.
+func (s *AuditService) Record(entry AuditEntry) error {
+	if err := s.append(entry); err != nil {
+		s.log.WithError(err).WithFields(logrus.Fields{
+			"action":     entry.Action,
+			"request_id": entry.RequestID,
+		}).Error("failed to write audit entry")
+		return err
+	}
+	return nil
+}
+
+// TryRecord добавляет запись в журнал для уже выполненного действия, которое нельзя откатить
+// из-за сбоя аудита. Ошибка только логируется.
+func (s *AuditService) TryRecord(entry AuditEntry) {
+	_ = s.Record(entry)
+}
+
+func (s *AuditService) append(entry AuditEntry) error {
+	details, err := canonicalJSON(entry.Details)
+	if err != nil {
+		return err
+	}
+	before, err := optionalJSON(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := optionalJSON(entry.After)
+	if err != nil {
+		return err
+	}
+
+	return s.auditRepo.Append(func(prevHash string) (*models.AuditLog, error) {
+		record := &models.AuditLog{
+			ActorID:     entry.ActorID,
+			Action:      entry.Action,
+			EntityType:  entry.EntityType,
+			EntityID:    entry.EntityID,
+			IP:          entry.IP,
+			UserAgent:   entry.UserAgent,
+			RequestID:   entry.RequestID,
+			Details:     details,
+			BeforeValue: before,
+			AfterValue:  after,
+			CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+		}
+		hash, err := auditHash(prevHash, record)
+		if err != nil {
+			return nil, err
+		}
+		record.PrevHash = &prevHash
+		record.Hash = &hash
+		return record, nil
+	})
+}
+
+func (s *AuditService) Find(filter repositories.AuditLogFilter, limit int, offset int) ([]models.AuditLog, error) {
+	return s.auditRepo.Find(filter, limit, offset)
+}
+
+// VerifyChain пересчитывает хеши всех записей и находит первую запись, нарушающую цепочку.
+// Записи без хеша допустимы только в начале журнала — они появились до включения цепочки.
+func (s *AuditService) VerifyChain() (*AuditChainReport, error) {
+	report := &AuditChainReport{Valid: true}
+	prevHash := ""
+	chainStarted := false
+
+	err := s.auditRepo.FindInBatches(500, func(batch []models.AuditLog) error {
+		for i := range batch {
+			if !report.Valid {
+				return nil
+			}
+			record := &batch[i]
+			report.Checked++
+
+			if record.Hash == nil {
+				if chainStarted {
+					s.markBroken(report, record.ID)
+				} else {
+					report.Legacy++
+				}
+				continue
+			}
+			chainStarted = true
+
+			expected, err := auditHash(prevHash, record)
+			if err != nil {
+				return err
+			}
+			if record.PrevHash == nil || *record.PrevHash != prevHash || *record.Hash != expected {
+				s.markBroken(report, record.ID)
+				continue
+			}
+			prevHash = *record.Hash
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *AuditService) markBroken(report *AuditChainReport, id uint) {
+	report.Valid = false
+	report.BrokenAtID = &id
+	s.log.Error("audit chain is broken at record " + strconv.Itoa(int(id)))
+}
+
+// auditHash — sha256 от хеша предыдущей записи и всех значимых полей записи
+func auditHash(prevHash string, record *models.AuditLog) (string, error) {
+	details, err := normalizeJSON(record.Details)
+	if err != nil {
+		return "", err
+	}
+	before, err := normalizeOptionalJSON(record.BeforeValue)
+	if err != nil {
+		return "", err
+	}
+	after, err := normalizeOptionalJSON(record.AfterValue)
+	if err != nil {
+		return "", err
+	}
+
+	actor := ""
+	if record.ActorID != nil {
+		actor = strconv.Itoa(int(*record.ActorID))
+	}
+
+	payload := strings.Join([]string{
+		prevHash,
+		actor,
+		record.Action,
+		record.EntityType,
+		record.EntityID,
+		record.IP,
+		record.UserAgent,
+		record.RequestID,
+		details,
+		before,
+		after,
+		record.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}, "\x1f")
+
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func canonicalJSON(value interface{}) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return normalizeJSON(string(raw))
+}
+
+func optionalJSON(value interface{}) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	result, err := canonicalJSON(value)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// normalizeJSON приводит JSON к единому виду: PostgreSQL хранит jsonb в своём формате,
+// поэтому хеш считается от нормализованного представления, а не от исходной строки
+func normalizeJSON(raw string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(normalized), nil
+}
+
+func normalizeOptionalJSON(raw *string) (string, error) {
+	if raw == nil {
+		return "", nil
+	}
+	return normalizeJSON(*raw)
+}
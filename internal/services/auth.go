@@ -4,12 +4,18 @@ import (
 	"BankSystem/internal/models"
 	"BankSystem/internal/repositories"
 	"BankSystem/internal/security"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	"net/http"
 )
 
+var (
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrSessionExpired = errors.New("session expired, please log in again")
+)
+
 type AuthService struct {
 	userRepo *repositories.UserRepository
 	log      *logrus.Logger
@@ -49,11 +55,13 @@ func (s *AuthService) GetCurrentUser(c *gin.Context) (*models.User, error) {
 	email, exists := c.Get("email")
 	if !exists {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, ErrUnauthorized
 	}
 
 	user, err := s.userRepo.FindByEmail(email.(string))
 	if err != nil || user == nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, ErrUserNotFound
 	}
 
 	return user, nil
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/repositories"
+	"BankSystem/internal/utils/pan"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+
+	accountservice "BankSystem/internal/services/account"
+)
+
+var (
+	ErrBeneficiaryNotFound = errors.New("beneficiary not found")
+	ErrBeneficiaryExists   = errors.New("beneficiary with this nickname already exists")
+	ErrBeneficiaryInactive = errors.New("beneficiary account or card does not exist or does not accept transfers")
+	ErrTemplateNotFound    = errors.New("payment template not found")
+)
+
+// TemplateExecution — результат перевода по шаблону
+type TemplateExecution struct {
+	Template    *models.PaymentTemplate
+	Beneficiary *models.Beneficiary
+	Balance     decimal.Decimal
+}
+
+// BeneficiaryService — адресная книга получателей и шаблоны переводов
+type BeneficiaryService struct {
+	beneficiaryRepo *repositories.BeneficiaryRepository
+	accountRepo     *repositories.AccountRepository
+	cardRepo        *repositories.CardRepository
+	cardService     *CardService
+	accountService  *accountservice.AccountService
+	log             *logrus.Logger
+}
+
+func NewBeneficiaryService(
+	beneficiaryRepo *repositories.BeneficiaryRepository,
+	accountRepo *repositories.AccountRepository,
+	cardRepo *repositories.CardRepository,
+	cardService *CardService,
+	accountService *accountservice.AccountService,
+	log *logrus.Logger) *BeneficiaryService {
+	return &BeneficiaryService{
+		beneficiaryRepo: beneficiaryRepo,
+		accountRepo:     accountRepo,
+		cardRepo:        cardRepo,
+		cardService:     cardService,
+		accountService:  accountService,
+		log:             log,
+	}
+}
+
+// CreateBeneficiary сохраняет получателя под уникальным для пользователя псевдонимом.
+// Карта ищется по номеру, в адресной книге остаются только ссылка на неё и маскированный номер.
+func (s *BeneficiaryService) CreateBeneficiary(userID uint, req dto.CreateBeneficiaryRequest) (*models.Beneficiary, error) {
+	nickname := strings.TrimSpace(req.Nickname)
+	existing, err := s.beneficiaryRepo.FindByNickname(userID, nickname)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrBeneficiaryExists
+	}
+
+	beneficiary := &models.Beneficiary{UserID: userID, Nickname: nickname}
+	if req.AccountID != 0 {
+		account, err := s.accountRepo.FindByID(req.AccountID)
+		if err != nil || account == nil || account.Status != models.AccountStatusActive {
+			return nil, ErrBeneficiaryInactive
+		}
+		beneficiary.Kind = models.BeneficiaryKindAccount
+		beneficiary.AccountID = &account.ID
+	} else {
+		// Отсутствующая и непригодная карта дают одну и ту же ошибку, чтобы по ответу нельзя было проверить номер
+		card, err := s.cardService.FindByNumber(req.CardNumber)
+		if err != nil || card == nil {
+			return nil, ErrBeneficiaryInactive
+		}
+		if _, err := s.cardTarget(card); err != nil {
+			return nil, ErrBeneficiaryInactive
+		}
+		masked := pan.Mask(req.CardNumber)
+		beneficiary.Kind = models.BeneficiaryKindCard
+		beneficiary.CardID = &card.ID
+		beneficiary.CardMasked = &masked
+	}
+
+	if err := s.beneficiaryRepo.Create(beneficiary); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("user " + strconv.Itoa(int(userID)) + " saved beneficiary " + strconv.Itoa(int(beneficiary.ID)))
+	return beneficiary, nil
+}
+
+func (s *BeneficiaryService) GetBeneficiaries(userID uint) ([]models.Beneficiary, error) {
+	return s.beneficiaryRepo.FindAllByUserID(userID)
+}
+
+// DeleteBeneficiary удаляет получателя и все шаблоны переводов ему
+func (s *BeneficiaryService) DeleteBeneficiary(id uint, userID uint) (*models.Beneficiary, error) {
+	beneficiary, err := s.beneficiaryRepo.FindByIDAndUserID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if beneficiary == nil {
+		return nil, ErrBeneficiaryNotFound
+	}
+	if err := s.beneficiaryRepo.Delete(beneficiary); err != nil {
+		return nil, err
+	}
+	return beneficiary, nil
+}
+
+// CreateTemplate сохраняет шаблон перевода с собственного аккаунта сохранённому получателю
+func (s *BeneficiaryService) CreateTemplate(userID uint, req dto.CreateTemplateRequest) (*models.PaymentTemplate, error) {
+	from, err := s.accountRepo.FindByIdAndUserID(req.FromAccountID, userID)
+	if err != nil || from == nil {
+		return nil, accountservice.ErrAccountNotFound
+	}
+	beneficiary, err := s.beneficiaryRepo.FindByIDAndUserID(req.BeneficiaryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if beneficiary == nil {
+		return nil, ErrBeneficiaryNotFound
+	}
+	if beneficiary.AccountID != nil && *beneficiary.AccountID == from.ID {
+		return nil, accountservice.ErrSameAccount
+	}
+
+	template := &models.PaymentTemplate{
+		UserID:        userID,
+		Name:          strings.TrimSpace(req.Name),
+		BeneficiaryID: beneficiary.ID,
+		FromAccountID: from.ID,
+		Amount:        decimal.NewFromFloat(req.Amount),
+	}
+	if req.Comment != "" {
+		template.Comment = &req.Comment
+	}
+	if err := s.beneficiaryRepo.CreateTemplate(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *BeneficiaryService) GetTemplates(userID uint) ([]models.PaymentTemplate, error) {
+	return s.beneficiaryRepo.FindTemplatesByUserID(userID)
+}
+
+func (s *BeneficiaryService) DeleteTemplate(id uint, userID uint) (*models.PaymentTemplate, error) {
+	template, err := s.beneficiaryRepo.FindTemplateByIDAndUserID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, ErrTemplateNotFound
+	}
+	if err := s.beneficiaryRepo.DeleteTemplate(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// ExecuteTemplate выполняет перевод по шаблону. Перед переводом проверяется, что получатель всё ещё существует
+// и принимает зачисления, а аккаунт списания по-прежнему принадлежит пользователю.
+// Перевод проходит тем же путём, что и /transfer/create, комментарий шаблона сохраняется в операции.
+func (s *BeneficiaryService) ExecuteTemplate(id uint, userID uint) (*TemplateExecution, error) {
+	template, err := s.beneficiaryRepo.FindTemplateByIDAndUserID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, ErrTemplateNotFound
+	}
+	beneficiary, err := s.beneficiaryRepo.FindByIDAndUserID(template.BeneficiaryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if beneficiary == nil {
+		return nil, ErrBeneficiaryNotFound
+	}
+	from, err := s.accountRepo.FindByIdAndUserID(template.FromAccountID, userID)
+	if err != nil || from == nil {
+		return nil, accountservice.ErrAccountNotFound
+	}
+	toAccountID, err := s.resolveTarget(beneficiary)
+	if err != nil {
+		return nil, err
+	}
+
+	details := accountservice.TransferDetails{Description: template.Comment}
+	var sender *models.Account
+	err = s.accountRepo.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		sender, _, err = s.accountService.TransferWithTx(tx, from.ID, toAccountID, template.Amount, details)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("executed payment template " + strconv.Itoa(int(template.ID)) + " of " + template.Amount.StringFixed(2))
+	return &TemplateExecution{Template: template, Beneficiary: beneficiary, Balance: sender.Balance}, nil
+}
+
+// resolveTarget возвращает аккаунт зачисления для получателя, если он всё ещё действует
+func (s *BeneficiaryService) resolveTarget(beneficiary *models.Beneficiary) (uint, error) {
+	if beneficiary.Kind == models.BeneficiaryKindAccount {
+		account, err := s.accountRepo.FindByID(*beneficiary.AccountID)
+		if err != nil || account == nil || account.Status != models.AccountStatusActive {
+			return 0, ErrBeneficiaryInactive
+		}
+		return account.ID, nil
+	}
+
+	card, err := s.cardRepo.FindByID(*beneficiary.CardID)
+	if err != nil || card == nil {
+		return 0, ErrBeneficiaryInactive
+	}
+	return s.cardTarget(card)
+}
+
+// cardTarget — зачислять на карту можно, пока она активна, не истекла и её аккаунт активен
+func (s *BeneficiaryService) cardTarget(card *models.Card) (uint, error) {
+	if card.Status != models.CardStatusActive || !time.Now().Before(card.ExpiredAt) {
+		return 0, ErrBeneficiaryInactive
+	}
+	account, err := s.accountRepo.FindByID(card.AccountId)
+	if err != nil || account == nil || account.Status != models.AccountStatusActive {
+		return 0, ErrBeneficiaryInactive
+	}
+	return account.ID, nil
+}
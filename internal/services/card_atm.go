@@ -0,0 +1,216 @@
+package services
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"errors"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+
+	accountservice "BankSystem/internal/services/account"
+)
+
+var (
+	ErrFeeAccountUnavailable = errors.New("bank fee account can not accept fees")
+	ErrCardNotPhysical       = errors.New("cash can be withdrawn with a physical card only")
+)
+
+// ATMWithdrawal — результат снятия наличных
+type ATMWithdrawal struct {
+	CardID  uint
+	Amount  decimal.Decimal
+	Fee     decimal.Decimal
+	Balance decimal.Decimal
+}
+
+// atmPipeline — проверки снятия наличных: вместо CVV проверяется PIN, вместо общего лимита оплат — лимиты на наличные.
+// Лимиты владельца карты действуют и на снятие.
+func (s *CardService) atmPipeline() []authorizationStep {
+	return []authorizationStep{
+		s.checkPAN,
+		s.checkNotExpired,
+		s.checkCardStatus,
+		s.checkPhysicalCard,
+		s.checkPIN,
+		s.checkAccountStatus,
+		s.checkCashLimits,
+		s.checkCardLimits,
+		s.checkCashBalance,
+	}
+}
+
+// WithdrawCash — снятие наличных в банкомате по номеру карты и PIN. С аккаунта списываются сумма и комиссия,
+// они записываются отдельными транзакциями withdrawal и fee; комиссия зачисляется на аккаунт комиссий банка.
+// При отказе возвращает *AuthorizationError.
+func (s *CardService) WithdrawCash(req dto.ATMWithdrawRequest) (*ATMWithdrawal, error) {
+	a := &cardAuthorization{
+		req:    dto.CardPaymentRequest{CardNumber: req.CardNumber, Amount: req.Amount},
+		amount: decimal.NewFromFloat(req.Amount),
+		now:    time.Now(),
+		pin:    req.PIN,
+	}
+	if err := runPipeline(a, s.atmPipeline()); err != nil {
+		return nil, err
+	}
+
+	cardID := a.card.ID
+	total := a.amount.Add(a.fee)
+	withdrawalDescription := "ATM cash withdrawal"
+	feeDescription := "ATM withdrawal fee"
+	var balance decimal.Decimal
+	err := s.accountRepo.WithinTransaction(func(tx *gorm.DB) error {
+		account, feeAccount, err := s.lockCashAccounts(tx, a)
+		if err != nil {
+			return err
+		}
+		if err := accountservice.CheckDebit(account); err != nil {
+			return err
+		}
+		// Лимиты перепроверяются под блокировкой карты, иначе параллельные снятия их превысят
+		card, err := s.cardRepo.FindByIDWithLock(tx, cardID)
+		if err != nil {
+			return decline(AuthCodeInvalidCard, "card not found")
+		}
+		a.card = card
+		if err := s.checkCardStatus(a); err != nil {
+			return err
+		}
+		if err := s.checkCashLimits(a); err != nil {
+			return err
+		}
+		if err := s.checkCardLimits(a); err != nil {
+			return err
+		}
+		if account.AvailableBalance().LessThan(total) {
+			return accountservice.ErrInsufficientFunds
+		}
+
+		account.Balance = account.Balance.Sub(total)
+		if err := s.accountRepo.UpdateWithTx(tx, account); err != nil {
+			return err
+		}
+		balance = account.Balance
+
+		if err := tx.Create(&models.Transaction{
+			FromAccountID:   account.ID,
+			ToAccountID:     account.ID,
+			Amount:          a.amount,
+			TransactionType: "withdrawal",
+			Currency:        account.Currency,
+			CardID:          &cardID,
+			Description:     &withdrawalDescription,
+		}).Error; err != nil {
+			return err
+		}
+		if err := s.closeSingleUse(tx, cardID, a.now); err != nil {
+			return err
+		}
+		if feeAccount == nil {
+			return nil
+		}
+
+		if err := accountservice.CheckCredit(feeAccount); err != nil {
+			return ErrFeeAccountUnavailable
+		}
+		if feeAccount.Currency != account.Currency {
+			return ErrFeeAccountUnavailable
+		}
+		feeAccount.Balance = feeAccount.Balance.Add(a.fee)
+		if err := s.accountRepo.UpdateWithTx(tx, feeAccount); err != nil {
+			return err
+		}
+		return tx.Create(&models.Transaction{
+			FromAccountID:   account.ID,
+			ToAccountID:     feeAccount.ID,
+			Amount:          a.fee,
+			TransactionType: "fee",
+			Currency:        account.Currency,
+			CardID:          &cardID,
+			Description:     &feeDescription,
+		}).Error
+	})
+	if err != nil {
+		return nil, debitError(err)
+	}
+
+	s.log.Info("cash withdrawal of " + a.amount.StringFixed(2) + " by card " + strconv.Itoa(int(cardID)))
+	return &ATMWithdrawal{CardID: cardID, Amount: a.amount, Fee: a.fee, Balance: balance}, nil
+}
+
+// lockCashAccounts блокирует аккаунт карты и, если есть комиссия, аккаунт комиссий банка (иначе nil)
+func (s *CardService) lockCashAccounts(tx *gorm.DB, a *cardAuthorization) (*models.Account, *models.Account, error) {
+	if !a.fee.IsPositive() {
+		account, err := s.accountRepo.FindByIDWithLock(tx, a.account.ID)
+		if err != nil {
+			return nil, nil, accountservice.ErrAccountNotFound
+		}
+		return account, nil, nil
+	}
+	account, feeAccount, err := s.accountService.LockPair(tx, a.account.ID, s.cfg.ATMFeeAccountID)
+	if err != nil {
+		return nil, nil, ErrFeeAccountUnavailable
+	}
+	return account, feeAccount, nil
+}
+
+// checkPhysicalCard — наличные снимаются только по пластиковой карте, виртуальные карты предназначены для оплат онлайн
+func (s *CardService) checkPhysicalCard(a *cardAuthorization) error {
+	if a.card.Kind != models.CardKindPhysical {
+		return decline(AuthCodeNotPermitted, ErrCardNotPhysical.Error())
+	}
+	return nil
+}
+
+func (s *CardService) checkPIN(a *cardAuthorization) error {
+	err := s.verifyPIN(a.card.ID, a.pin)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrIncorrectPIN), errors.Is(err, ErrPINNotSet):
+		return decline(AuthCodeIncorrectPIN, err.Error())
+	case errors.Is(err, ErrPINTriesExceeded):
+		return decline(AuthCodePINTriesExceeded, err.Error())
+	}
+	return err
+}
+
+// checkCashLimits проверяет лимит одного снятия и дневной лимит наличных по карте
+func (s *CardService) checkCashLimits(a *cardAuthorization) error {
+	if s.cfg.ATMMaxWithdrawal.IsPositive() && a.amount.GreaterThan(s.cfg.ATMMaxWithdrawal) {
+		return decline(AuthCodeExceedsAmountLimit, "amount exceeds the cash withdrawal limit")
+	}
+	if s.cfg.ATMDailyLimit.IsPositive() {
+		withdrawn, err := s.transactionRepo.SumCardWithdrawals(a.card.ID, startOfDay(a.now.UTC()))
+		if err != nil {
+			return err
+		}
+		if withdrawn.Add(a.amount).GreaterThan(s.cfg.ATMDailyLimit) {
+			return decline(AuthCodeExceedsAmountLimit, "daily cash withdrawal limit exceeded")
+		}
+	}
+	return nil
+}
+
+// checkCashBalance рассчитывает комиссию и проверяет, что доступного баланса хватает на сумму с комиссией
+func (s *CardService) checkCashBalance(a *cardAuthorization) error {
+	a.fee = s.atmFee(a.account.ID, a.amount)
+	if a.account.AvailableBalance().LessThan(a.amount.Add(a.fee)) {
+		return decline(AuthCodeInsufficientFunds, accountservice.ErrInsufficientFunds.Error())
+	}
+	return nil
+}
+
+// atmFee — комиссия за снятие: процент от суммы, но не меньше минимальной. Без аккаунта комиссий банка,
+// а также при снятии с самого этого аккаунта комиссия не взимается.
+func (s *CardService) atmFee(accountID uint, amount decimal.Decimal) decimal.Decimal {
+	if s.cfg.ATMFeeAccountID == 0 || s.cfg.ATMFeeAccountID == accountID {
+		return decimal.Zero
+	}
+	fee := amount.Mul(s.cfg.ATMFeePercent).Div(decimal.NewFromInt(100)).Round(2)
+	if fee.LessThan(s.cfg.ATMMinFee) {
+		return s.cfg.ATMMinFee
+	}
+	return fee
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/security"
+	"errors"
+	"github.com/shopspring/decimal"
+	"time"
+
+	accountservice "BankSystem/internal/services/account"
+)
+
+// Коды ответа авторизации в духе ISO 8583 (поле 39)
+const (
+	AuthCodeApproved           = "00"
+	AuthCodeInvalidMerchant    = "03"
+	AuthCodeDoNotHonor         = "05"
+	AuthCodeInvalidCard        = "14"
+	AuthCodeLostCard           = "41"
+	AuthCodeInsufficientFunds  = "51"
+	AuthCodeExpiredCard        = "54"
+	AuthCodeIncorrectPIN       = "55"
+	AuthCodeNotPermitted       = "57"
+	AuthCodeExceedsAmountLimit = "61"
+	AuthCodeRestrictedCard     = "62"
+	AuthCodeExceedsFrequency   = "65"
+	AuthCodePINTriesExceeded   = "75"
+	AuthCodeCVVFailed          = "82"
+	AuthCodeSystemMalfunction  = "96"
+	// AuthCodeAuthenticationRequired — оплата ожидает подтверждения одноразовым кодом
+	AuthCodeAuthenticationRequired = "1A"
+)
+
+// AuthorizationError — отказ в авторизации операции по карте с кодом ответа
+type AuthorizationError struct {
+	Code   string
+	Reason string
+}
+
+func (e *AuthorizationError) Error() string {
+	return e.Reason
+}
+
+func decline(code string, reason string) *AuthorizationError {
+	return &AuthorizationError{Code: code, Reason: reason}
+}
+
+// AuthorizationCode — код ответа для ошибки операции по карте, 96 для непредвиденных ошибок
+func AuthorizationCode(err error) string {
+	if err == nil {
+		return AuthCodeApproved
+	}
+	var authErr *AuthorizationError
+	if errors.As(err, &authErr) {
+		return authErr.Code
+	}
+	return AuthCodeSystemMalfunction
+}
+
+// cardAuthorization — данные, накапливаемые по ходу проверок
+type cardAuthorization struct {
+	req      dto.CardPaymentRequest
+	amount   decimal.Decimal
+	now      time.Time
+	merchant *models.Merchant
+	card     *models.Card
+	account  *models.Account
+	// token — токен мерчанта, по которому проводится оплата без номера карты и CVV
+	token     string
+	cardToken *models.CardToken
+	// pin и fee — PIN-код и комиссия при снятии наличных
+	pin string
+	fee decimal.Decimal
+	// userID, recipientNumber и recipientCard — отправитель и карта получателя при переводе с карты на карту
+	userID          uint
+	recipientNumber string
+	recipientCard   *models.Card
+}
+
+// authorizationStep — одна проверка; nil означает, что проверка пройдена
+type authorizationStep func(a *cardAuthorization) error
+
+// authorizationPipeline — проверки выполняются строго в этом порядке, первый отказ прерывает авторизацию
+func (s *CardService) authorizationPipeline() []authorizationStep {
+	return []authorizationStep{
+		s.checkMerchant,
+		s.checkPAN,
+		s.checkExpiry,
+		s.checkCVV,
+		s.checkCardStatus,
+		s.checkAccountStatus,
+		s.checkLimits,
+		s.checkBalance,
+	}
+}
+
+// tokenPipeline — проверки оплаты по токену: вместо номера карты и CVV проверяется токен мерчанта
+func (s *CardService) tokenPipeline() []authorizationStep {
+	return []authorizationStep{
+		s.checkMerchant,
+		s.checkToken,
+		s.checkNotExpired,
+		s.checkCardStatus,
+		s.checkAccountStatus,
+		s.checkLimits,
+		s.checkBalance,
+	}
+}
+
+// authorize прогоняет операцию через все проверки и возвращает карту и аккаунт для списания
+func (s *CardService) authorize(req dto.CardPaymentRequest) (*cardAuthorization, error) {
+	a := &cardAuthorization{
+		req:    req,
+		amount: decimal.NewFromFloat(req.Amount),
+		now:    time.Now(),
+	}
+	return a, runPipeline(a, s.authorizationPipeline())
+}
+
+// authorizeToken — authorize для оплаты мерчантом по токену карты
+func (s *CardService) authorizeToken(merchantID uint, token string, amount float64) (*cardAuthorization, error) {
+	a := &cardAuthorization{
+		req:    dto.CardPaymentRequest{MerchantID: merchantID, Amount: amount},
+		amount: decimal.NewFromFloat(amount),
+		now:    time.Now(),
+		token:  token,
+	}
+	return a, runPipeline(a, s.tokenPipeline())
+}
+
+func runPipeline(a *cardAuthorization, steps []authorizationStep) error {
+	for _, step := range steps {
+		if err := step(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkMerchant — мерчант существует и его расчётный аккаунт может принимать зачисления
+func (s *CardService) checkMerchant(a *cardAuthorization) error {
+	merchant, err := s.merchantRepo.FindByID(a.req.MerchantID)
+	if err != nil || merchant == nil {
+		return decline(AuthCodeInvalidMerchant, "merchant not found")
+	}
+	settlement, err := s.accountRepo.FindByID(merchant.SettlementAccountID)
+	if err != nil || settlement == nil || !settlement.CanCredit() {
+		return decline(AuthCodeInvalidMerchant, "merchant can not accept payments")
+	}
+	a.merchant = merchant
+	return nil
+}
+
+func (s *CardService) checkPAN(a *cardAuthorization) error {
+	card, err := s.FindByNumber(a.req.CardNumber)
+	if err != nil || card == nil {
+		return decline(AuthCodeInvalidCard, "card not found")
+	}
+	a.card = card
+	return nil
+}
+
+// checkExpiry сверяет срок действия из запроса (месяц и год) с картой и проверяет, что он не истёк
+func (s *CardService) checkExpiry(a *cardAuthorization) error {
+	expiry := a.card.ExpiredAt.UTC()
+	requested := a.req.ExpiredAt.UTC()
+	if expiry.Year() != requested.Year() || expiry.Month() != requested.Month() {
+		return decline(AuthCodeExpiredCard, "expiry date does not match")
+	}
+	return s.checkNotExpired(a)
+}
+
+func (s *CardService) checkNotExpired(a *cardAuthorization) error {
+	if a.card.Status == models.CardStatusExpired || !a.now.Before(a.card.ExpiredAt) {
+		return decline(AuthCodeExpiredCard, ErrCardExpired.Error())
+	}
+	return nil
+}
+
+// checkToken ищет карту по токену, выданному мерчанту операции
+func (s *CardService) checkToken(a *cardAuthorization) error {
+	token, err := s.tokenRepo.FindActiveByHash(security.HashOpaqueToken(a.token), a.merchant.ID)
+	if err != nil || token == nil {
+		return decline(AuthCodeInvalidCard, ErrCardTokenNotFound.Error())
+	}
+	card, err := s.cardRepo.FindByID(token.CardID)
+	if err != nil || card == nil {
+		return decline(AuthCodeInvalidCard, "card not found")
+	}
+	a.card = card
+	a.cardToken = token
+	return nil
+}
+
+func (s *CardService) checkCVV(a *cardAuthorization) error {
+	if !s.validateCVV(a.req.Cvv, a.card.Cvv) {
+		return decline(AuthCodeCVVFailed, "CVV is not valid")
+	}
+	return nil
+}
+
+func (s *CardService) checkCardStatus(a *cardAuthorization) error {
+	switch a.card.Status {
+	case models.CardStatusActive:
+		return nil
+	case models.CardStatusLost:
+		return decline(AuthCodeLostCard, ErrCardLost.Error())
+	case models.CardStatusBlocked:
+		return decline(AuthCodeRestrictedCard, ErrCardBlocked.Error())
+	}
+	return decline(AuthCodeRestrictedCard, ErrCardClosed.Error())
+}
+
+func (s *CardService) checkAccountStatus(a *cardAuthorization) error {
+	account, err := s.accountRepo.FindByID(a.card.AccountId)
+	if err != nil || account == nil {
+		return decline(AuthCodeDoNotHonor, "card account not found")
+	}
+	if !account.CanDebit() {
+		return decline(AuthCodeNotPermitted, "account does not allow debit operations")
+	}
+	if a.merchant != nil && account.ID == a.merchant.SettlementAccountID {
+		return decline(AuthCodeNotPermitted, "card account is the merchant settlement account")
+	}
+	a.account = account
+	return nil
+}
+
+// checkLimits проверяет общий лимит банка на одну оплату и лимиты карты
+func (s *CardService) checkLimits(a *cardAuthorization) error {
+	if s.cfg.MaxPaymentAmount.IsPositive() && a.amount.GreaterThan(s.cfg.MaxPaymentAmount) {
+		return decline(AuthCodeExceedsAmountLimit, "amount exceeds the payment limit")
+	}
+	return s.checkCardLimits(a)
+}
+
+// checkCardLimits проверяет ограничения виртуальной карты и лимиты, заданные владельцем карты.
+// Суммы за день и месяц считаются по календарным периодам UTC, количество операций — за последний час.
+func (s *CardService) checkCardLimits(a *cardAuthorization) error {
+	card := a.card
+	if card.SingleUse {
+		count, err := s.paymentsSince(card.ID, time.Time{})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return decline(AuthCodeRestrictedCard, "single use card has already been used")
+		}
+	}
+	if card.SpendingCap != nil {
+		spent, err := s.spentSince(card.ID, time.Time{})
+		if err != nil {
+			return err
+		}
+		if spent.Add(a.amount).GreaterThan(*card.SpendingCap) {
+			return decline(AuthCodeExceedsAmountLimit, "card spending cap exceeded")
+		}
+	}
+	if card.PerTransactionLimit != nil && a.amount.GreaterThan(*card.PerTransactionLimit) {
+		return decline(AuthCodeExceedsAmountLimit, "amount exceeds the card per-transaction limit")
+	}
+
+	now := a.now.UTC()
+	if card.DailyLimit != nil {
+		spent, err := s.spentSince(card.ID, startOfDay(now))
+		if err != nil {
+			return err
+		}
+		if spent.Add(a.amount).GreaterThan(*card.DailyLimit) {
+			return decline(AuthCodeExceedsAmountLimit, "card daily spending limit exceeded")
+		}
+	}
+	if card.MonthlyLimit != nil {
+		spent, err := s.spentSince(card.ID, startOfMonth(now))
+		if err != nil {
+			return err
+		}
+		if spent.Add(a.amount).GreaterThan(*card.MonthlyLimit) {
+			return decline(AuthCodeExceedsAmountLimit, "card monthly spending limit exceeded")
+		}
+	}
+	if card.HourlyPaymentsLimit != nil {
+		count, err := s.paymentsSince(card.ID, now.Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+		if count >= int64(*card.HourlyPaymentsLimit) {
+			return decline(AuthCodeExceedsFrequency, "card hourly payments limit exceeded")
+		}
+	}
+	return nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func (s *CardService) checkBalance(a *cardAuthorization) error {
+	if a.account.AvailableBalance().LessThan(a.amount) {
+		return decline(AuthCodeInsufficientFunds, accountservice.ErrInsufficientFunds.Error())
+	}
+	return nil
+}
+
+// debitError — отказ для ошибки списания, возникшей после проверок (например, при параллельной операции)
+func debitError(err error) error {
+	switch {
+	case errors.Is(err, accountservice.ErrInsufficientFunds):
+		return decline(AuthCodeInsufficientFunds, err.Error())
+	case accountservice.IsStatusError(err):
+		return decline(AuthCodeNotPermitted, err.Error())
+	}
+	return err
+}
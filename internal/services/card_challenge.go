@@ -0,0 +1,129 @@
+package services
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/utils"
+	"BankSystem/internal/utils/pan"
+	"errors"
+	"github.com/shopspring/decimal"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// otpLength — количество цифр одноразового кода
+const otpLength = 6
+
+var (
+	ErrHoldNotChallenged   = errors.New("payment does not wait for confirmation")
+	ErrIncorrectOTP        = errors.New("incorrect confirmation code")
+	ErrOTPExpired          = errors.New("confirmation code has expired")
+	ErrOTPAttemptsExceeded = errors.New("confirmation attempts exceeded, payment is cancelled")
+)
+
+// requiresChallenge — нужно ли подтверждать оплату одноразовым кодом
+func (s *CardService) requiresChallenge(amount decimal.Decimal) bool {
+	return s.cfg.OTPThreshold.IsPositive() && amount.GreaterThan(s.cfg.OTPThreshold)
+}
+
+// challenge переводит новый резерв в статус challenged и возвращает одноразовый код.
+// Пока код не подтверждён, резерв живёт OTPTTL.
+func (s *CardService) challenge(hold *models.PaymentHold, captureOnConfirm bool) (string, error) {
+	code, err := utils.RandomDigits(otpLength)
+	if err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	otpHash := string(hash)
+	hold.Status = models.HoldStatusChallenged
+	hold.OtpHash = &otpHash
+	hold.CaptureOnConfirm = captureOnConfirm
+	hold.ExpiresAt = time.Now().UTC().Add(s.cfg.OTPTTL)
+	return code, nil
+}
+
+// ConfirmPayment проверяет одноразовый код оплаты. Верный код переводит резерв в authorized,
+// а одноэтапную оплату сразу списывает. После OTPMaxAttempts неверных кодов резерв отменяется.
+// Подтверждать оплату может только пользователь, который её инициировал.
+func (s *CardService) ConfirmPayment(holdID uint, userID uint, code string) (*models.PaymentHold, error) {
+	var hold *models.PaymentHold
+	var result error
+	err := s.holdRepo.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		hold, err = s.holdRepo.FindByIDWithLock(tx, holdID)
+		if err != nil || hold.InitiatorUserID == nil || *hold.InitiatorUserID != userID {
+			return ErrHoldNotFound
+		}
+		if hold.Status != models.HoldStatusChallenged || hold.OtpHash == nil {
+			return ErrHoldNotChallenged
+		}
+
+		now := time.Now().UTC()
+		if !now.Before(hold.ExpiresAt) {
+			result = ErrOTPExpired
+			return s.releaseHold(tx, hold, models.HoldStatusExpired)
+		}
+
+		if bcrypt.CompareHashAndPassword([]byte(*hold.OtpHash), []byte(code)) != nil {
+			hold.OtpAttempts++
+			if hold.OtpAttempts >= s.cfg.OTPMaxAttempts {
+				result = ErrOTPAttemptsExceeded
+				return s.releaseHold(tx, hold, models.HoldStatusVoided)
+			}
+			result = ErrIncorrectOTP
+			return s.holdRepo.UpdateWithTx(tx, hold)
+		}
+
+		hold.Status = models.HoldStatusAuthorized
+		hold.OtpHash = nil
+		hold.ExpiresAt = now.Add(s.cfg.HoldTTL)
+		return s.holdRepo.UpdateWithTx(tx, hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result != nil {
+		return nil, result
+	}
+
+	s.log.Info("payment " + strconv.Itoa(int(hold.ID)) + " confirmed")
+	if !hold.CaptureOnConfirm {
+		return hold, nil
+	}
+	captured, _, err := s.payNow(hold)
+	return captured, err
+}
+
+// sendOTP отправляет код владельцу карты. Ошибка отправки не отменяет резерв: он истечёт через OTPTTL.
+func (s *CardService) sendOTP(hold *models.PaymentHold, account *models.Account, code string) {
+	user, err := s.userRepo.FindByID(account.UserID)
+	if err != nil || user == nil {
+		s.log.Warning("could not find card owner to send confirmation code for payment " + strconv.Itoa(int(hold.ID)))
+		return
+	}
+
+	masked := ""
+	card, err := s.cardRepo.FindByID(hold.CardID)
+	if err == nil && card != nil {
+		if number, err := s.decryptNumber(card.CardNumber, card.CardKeyID); err == nil {
+			masked = pan.Mask(number)
+		}
+	}
+
+	notification := dto.PaymentOTPNotification{
+		To:         user.Email,
+		Name:       user.Username,
+		Code:       code,
+		CardMasked: masked,
+		Amount:     hold.Amount,
+		ExpiresAt:  hold.ExpiresAt,
+	}
+	if err := s.mailService.SendPaymentOTP(notification); err != nil {
+		s.log.Warning("could not send confirmation code for payment " + strconv.Itoa(int(hold.ID)) + ": " + err.Error())
+	}
+}
@@ -0,0 +1,497 @@
+package services
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/utils/pan"
+	"errors"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+
+	accountservice "BankSystem/internal/services/account"
+)
+
+// expiredHoldsBatch — сколько просроченных резервов снимается за один проход
+const expiredHoldsBatch = 100
+
+var (
+	ErrHoldNotFound          = errors.New("payment not found")
+	ErrHoldNotAuthorized     = errors.New("payment is not in authorized state")
+	ErrHoldExpired           = errors.New("payment authorization has expired")
+	ErrHoldNotRefundable     = errors.New("payment has no captured amount to refund")
+	ErrCaptureExceedsHold    = errors.New("capture amount exceeds authorized amount")
+	ErrRefundExceedsCaptured = errors.New("refund amount exceeds captured amount")
+	ErrMerchantNotFound      = errors.New("merchant not found")
+)
+
+// PayWithCard — одноэтапная оплата: авторизация и немедленное списание всей суммы.
+// Если оплата требует подтверждения кодом, возвращается резерв в статусе challenged без списания.
+// При отказе возвращает *AuthorizationError с кодом ответа.
+func (s *CardService) PayWithCard(req dto.CardPaymentRequest, initiatorID uint) (*models.PaymentHold, decimal.Decimal, error) {
+	auth, err := s.authorize(req)
+	if err != nil {
+		return nil, decimal.Zero, err
+	}
+	hold, err := s.reserve(auth, initiatorID, true)
+	if err != nil {
+		return nil, decimal.Zero, err
+	}
+	if hold.Status == models.HoldStatusChallenged {
+		return hold, decimal.Zero, nil
+	}
+	return s.payNow(hold)
+}
+
+// AuthorizePayment проверяет операцию и резервирует сумму на аккаунте карты.
+// Резерв уменьшает доступный баланс до списания, отмены или истечения срока.
+// Если оплата требует подтверждения кодом, резерв создаётся в статусе challenged.
+func (s *CardService) AuthorizePayment(req dto.CardPaymentRequest, initiatorID uint) (*models.PaymentHold, error) {
+	auth, err := s.authorize(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.reserve(auth, initiatorID, false)
+}
+
+// payNow списывает только что авторизованный резерв целиком; при ошибке списания резерв отменяется
+func (s *CardService) payNow(hold *models.PaymentHold) (*models.PaymentHold, decimal.Decimal, error) {
+	// Одноэтапную оплату списывает сам инициатор, поэтому владелец резерва не проверяется
+	captured, balance, err := s.capture(hold.ID, nil, nil)
+	if err != nil {
+		if _, voidErr := s.void(hold.ID, nil); voidErr != nil {
+			s.log.Error("could not void payment " + strconv.Itoa(int(hold.ID)) + ": " + voidErr.Error())
+		}
+		return nil, decimal.Zero, debitError(err)
+	}
+	return captured, balance, nil
+}
+
+// reserve резервирует сумму прошедшей проверки операции. Оплата по номеру карты выше порога
+// переводится в статус challenged, а владельцу карты отправляется одноразовый код.
+// captureOnConfirm — списать резерв сразу после подтверждения кода.
+func (s *CardService) reserve(auth *cardAuthorization, initiatorID uint, captureOnConfirm bool) (*models.PaymentHold, error) {
+	hold := &models.PaymentHold{
+		MerchantID:      &auth.merchant.ID,
+		CardID:          auth.card.ID,
+		AccountID:       auth.account.ID,
+		InitiatorUserID: &initiatorID,
+		Amount:          auth.amount,
+		CapturedAmount:  decimal.Zero,
+		RefundedAmount:  decimal.Zero,
+		Currency:        auth.account.Currency,
+		Status:          models.HoldStatusAuthorized,
+		ExpiresAt:       time.Now().UTC().Add(s.cfg.HoldTTL),
+	}
+	if auth.cardToken != nil {
+		hold.TokenID = &auth.cardToken.ID
+	}
+	otp := ""
+	if auth.cardToken == nil && s.requiresChallenge(auth.amount) {
+		var err error
+		if otp, err = s.challenge(hold, captureOnConfirm); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.holdRepo.WithinTransaction(func(tx *gorm.DB) error {
+		account, err := s.accountRepo.FindByIDWithLock(tx, auth.account.ID)
+		if err != nil {
+			return decline(AuthCodeDoNotHonor, "card account not found")
+		}
+		// Баланс и статус перепроверяются под блокировкой: они могли измениться после проверок
+		if !account.CanDebit() {
+			return decline(AuthCodeNotPermitted, "account does not allow debit operations")
+		}
+		if account.AvailableBalance().LessThan(auth.amount) {
+			return decline(AuthCodeInsufficientFunds, accountservice.ErrInsufficientFunds.Error())
+		}
+		// Лимиты карты тоже перепроверяются под блокировкой карты: параллельные оплаты ждут здесь,
+		// пока предыдущая не зафиксирует свой резерв. Карта блокируется после аккаунта, как при списании.
+		card, err := s.cardRepo.FindByIDWithLock(tx, auth.card.ID)
+		if err != nil {
+			return decline(AuthCodeInvalidCard, "card not found")
+		}
+		auth.card = card
+		if err := s.checkCardStatus(auth); err != nil {
+			return err
+		}
+		if err := s.checkLimits(auth); err != nil {
+			return err
+		}
+
+		account.HoldAmount = account.HoldAmount.Add(auth.amount)
+		if err := s.accountRepo.UpdateWithTx(tx, account); err != nil {
+			return err
+		}
+		return s.holdRepo.CreateWithTx(tx, hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if otp != "" {
+		s.sendOTP(hold, auth.account, otp)
+		s.log.Info("payment " + strconv.Itoa(int(hold.ID)) + " requires confirmation for card " + strconv.Itoa(int(hold.CardID)))
+		return hold, nil
+	}
+
+	s.log.Info("payment " + strconv.Itoa(int(hold.ID)) + " authorized for card " + strconv.Itoa(int(hold.CardID)))
+	return hold, nil
+}
+
+// CapturePayment списывает зарезервированную сумму полностью или частично (amount).
+// Остаток резерва освобождается, повторное списание по тому же резерву невозможно.
+func (s *CardService) CapturePayment(holdID uint, userID uint, amount *decimal.Decimal) (*models.PaymentHold, error) {
+	hold, _, err := s.capture(holdID, &userID, amount)
+	return hold, err
+}
+
+// VoidPayment отменяет резерв до списания
+func (s *CardService) VoidPayment(holdID uint, userID uint) (*models.PaymentHold, error) {
+	return s.void(holdID, &userID)
+}
+
+func (s *CardService) void(holdID uint, userID *uint) (*models.PaymentHold, error) {
+	var hold *models.PaymentHold
+	err := s.holdRepo.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		hold, err = s.lockHold(tx, holdID, userID)
+		if err != nil {
+			return err
+		}
+		// Оплату, ожидающую подтверждения кодом, тоже можно отменить
+		if !hold.IsReserved() {
+			return ErrHoldNotAuthorized
+		}
+		return s.releaseHold(tx, hold, models.HoldStatusVoided)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("payment " + strconv.Itoa(int(holdID)) + " voided")
+	return hold, nil
+}
+
+// RefundPayment возвращает на аккаунт карты списанную сумму полностью или частично (amount)
+func (s *CardService) RefundPayment(holdID uint, userID uint, amount *decimal.Decimal) (*models.PaymentHold, error) {
+	var hold *models.PaymentHold
+	err := s.holdRepo.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		hold, err = s.lockHold(tx, holdID, &userID)
+		if err != nil {
+			return err
+		}
+		if hold.Status != models.HoldStatusCaptured || !hold.Refundable().IsPositive() {
+			return ErrHoldNotRefundable
+		}
+
+		refund := hold.Refundable()
+		if amount != nil {
+			refund = *amount
+		}
+		if refund.GreaterThan(hold.Refundable()) {
+			return ErrRefundExceedsCaptured
+		}
+
+		account, settlement, err := s.lockCounterparties(tx, hold)
+		if err != nil {
+			return err
+		}
+		if err := accountservice.CheckCredit(account); err != nil {
+			return err
+		}
+
+		// Возврат списывается с расчётного аккаунта мерчанта, у которого должно хватать доступных средств
+		fromAccountID := account.ID
+		if settlement != nil {
+			if err := accountservice.CheckDebit(settlement); err != nil {
+				return err
+			}
+			if settlement.AvailableBalance().LessThan(refund) {
+				return accountservice.ErrInsufficientFunds
+			}
+			settlement.Balance = settlement.Balance.Sub(refund)
+			if err := s.accountRepo.UpdateWithTx(tx, settlement); err != nil {
+				return err
+			}
+			fromAccountID = settlement.ID
+		}
+
+		account.Balance = account.Balance.Add(refund)
+		if err := s.accountRepo.UpdateWithTx(tx, account); err != nil {
+			return err
+		}
+
+		hold.RefundedAmount = hold.RefundedAmount.Add(refund)
+		if !hold.Refundable().IsPositive() {
+			hold.Status = models.HoldStatusRefunded
+		}
+		if err := s.holdRepo.UpdateWithTx(tx, hold); err != nil {
+			return err
+		}
+
+		return tx.Create(&models.Transaction{
+			FromAccountID:   fromAccountID,
+			ToAccountID:     account.ID,
+			Amount:          refund,
+			TransactionType: "refund",
+			Currency:        hold.Currency,
+			CardID:          &hold.CardID,
+			HoldID:          &hold.ID,
+			MerchantID:      hold.MerchantID,
+			Description:     s.holdDescription(hold, "Card refund"),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("payment " + strconv.Itoa(int(holdID)) + " refunded")
+	return hold, nil
+}
+
+// ExpireHolds снимает резервы, не списанные до истечения срока, возвращает их количество
+func (s *CardService) ExpireHolds() (int, error) {
+	expired := 0
+	for {
+		ids, err := s.holdRepo.FindExpiredIDs(time.Now().UTC(), expiredHoldsBatch)
+		if err != nil {
+			return expired, err
+		}
+
+		for _, id := range ids {
+			err := s.holdRepo.WithinTransaction(func(tx *gorm.DB) error {
+				hold, err := s.holdRepo.FindByIDWithLock(tx, id)
+				if err != nil {
+					return err
+				}
+				// Резерв мог быть списан или отменён после выборки
+				if !hold.IsReserved() {
+					return nil
+				}
+				expired++
+				return s.releaseHold(tx, hold, models.HoldStatusExpired)
+			})
+			if err != nil {
+				return expired, err
+			}
+		}
+
+		if len(ids) < expiredHoldsBatch {
+			break
+		}
+	}
+
+	if expired > 0 {
+		s.log.Info("released " + strconv.Itoa(expired) + " expired payment holds")
+	}
+	return expired, nil
+}
+
+// capture списывает резерв и возвращает новый баланс аккаунта карты
+func (s *CardService) capture(holdID uint, userID *uint, amount *decimal.Decimal) (*models.PaymentHold, decimal.Decimal, error) {
+	var hold *models.PaymentHold
+	var account *models.Account
+	var transaction *models.Transaction
+
+	err := s.holdRepo.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		hold, err = s.lockHold(tx, holdID, userID)
+		if err != nil {
+			return err
+		}
+		if hold.Status != models.HoldStatusAuthorized {
+			return ErrHoldNotAuthorized
+		}
+
+		now := time.Now().UTC()
+		if !now.Before(hold.ExpiresAt) {
+			return ErrHoldExpired
+		}
+
+		captured := hold.Amount
+		if amount != nil {
+			captured = *amount
+		}
+		if captured.GreaterThan(hold.Amount) {
+			return ErrCaptureExceedsHold
+		}
+
+		var settlement *models.Account
+		account, settlement, err = s.lockCounterparties(tx, hold)
+		if err != nil {
+			return err
+		}
+		if err := accountservice.CheckDebit(account); err != nil {
+			return err
+		}
+
+		account.HoldAmount = account.HoldAmount.Sub(hold.Amount)
+		account.Balance = account.Balance.Sub(captured)
+		if err := s.accountRepo.UpdateWithTx(tx, account); err != nil {
+			return err
+		}
+
+		toAccountID := account.ID
+		if settlement != nil {
+			if err := accountservice.CheckCredit(settlement); err != nil {
+				return err
+			}
+			settlement.Balance = settlement.Balance.Add(captured)
+			if err := s.accountRepo.UpdateWithTx(tx, settlement); err != nil {
+				return err
+			}
+			toAccountID = settlement.ID
+		}
+
+		hold.Status = models.HoldStatusCaptured
+		hold.CapturedAmount = captured
+		hold.CapturedAt = &now
+		hold.ReleasedAt = &now
+		if err := s.holdRepo.UpdateWithTx(tx, hold); err != nil {
+			return err
+		}
+		if err := s.closeSingleUse(tx, hold.CardID, now); err != nil {
+			return err
+		}
+
+		transaction = &models.Transaction{
+			FromAccountID:   account.ID,
+			ToAccountID:     toAccountID,
+			Amount:          captured,
+			TransactionType: "payment",
+			Currency:        hold.Currency,
+			CardID:          &hold.CardID,
+			HoldID:          &hold.ID,
+			MerchantID:      hold.MerchantID,
+			Description:     s.holdDescription(hold, "Card payment"),
+		}
+		return tx.Create(transaction).Error
+	})
+	if err != nil {
+		return nil, decimal.Zero, err
+	}
+
+	s.notifyPayment(hold, account, transaction)
+	s.log.Info("payment " + strconv.Itoa(int(holdID)) + " captured")
+	return hold, account.Balance, nil
+}
+
+// lockHold блокирует резерв. Управлять оплатой может владелец мерчанта, а оплатой без мерчанта —
+// пользователь, который её авторизовал. Чужие оплаты считаются ненайденными. userID == nil — без проверки.
+func (s *CardService) lockHold(tx *gorm.DB, holdID uint, userID *uint) (*models.PaymentHold, error) {
+	hold, err := s.holdRepo.FindByIDWithLock(tx, holdID)
+	if err != nil {
+		return nil, ErrHoldNotFound
+	}
+	if userID == nil {
+		return hold, nil
+	}
+
+	if hold.MerchantID != nil {
+		merchant, err := s.merchantRepo.FindByIDAndOwnerID(*hold.MerchantID, *userID)
+		if err != nil || merchant == nil {
+			return nil, ErrHoldNotFound
+		}
+		return hold, nil
+	}
+
+	if hold.InitiatorUserID == nil || *hold.InitiatorUserID != *userID {
+		return nil, ErrHoldNotFound
+	}
+	return hold, nil
+}
+
+// lockCounterparties блокирует аккаунт карты и расчётный аккаунт мерчанта (nil для оплат без мерчанта)
+func (s *CardService) lockCounterparties(tx *gorm.DB, hold *models.PaymentHold) (*models.Account, *models.Account, error) {
+	if hold.MerchantID == nil {
+		account, err := s.accountRepo.FindByIDWithLock(tx, hold.AccountID)
+		if err != nil {
+			return nil, nil, accountservice.ErrAccountNotFound
+		}
+		return account, nil, nil
+	}
+
+	merchant, err := s.merchantRepo.FindByID(*hold.MerchantID)
+	if err != nil || merchant == nil {
+		return nil, nil, ErrMerchantNotFound
+	}
+	return s.accountService.LockPair(tx, hold.AccountID, merchant.SettlementAccountID)
+}
+
+// closeSingleUse закрывает одноразовую карту после успешной оплаты
+func (s *CardService) closeSingleUse(tx *gorm.DB, cardID uint, now time.Time) error {
+	card, err := s.cardRepo.FindByIDWithLock(tx, cardID)
+	if err != nil {
+		return ErrCardNotFound
+	}
+	if !card.SingleUse || card.Status == models.CardStatusClosed {
+		return nil
+	}
+	card.Status = models.CardStatusClosed
+	card.StatusChangedAt = &now
+	return s.cardRepo.UpdateWithTx(tx, card)
+}
+
+// holdDescription — описание операции по резерву для истории: operation и название мерчанта, если он есть
+func (s *CardService) holdDescription(hold *models.PaymentHold, operation string) *string {
+	description := operation
+	if hold.MerchantID != nil {
+		merchant, err := s.merchantRepo.FindByID(*hold.MerchantID)
+		if err == nil && merchant != nil {
+			description += ": " + merchant.Name
+		}
+	}
+	return &description
+}
+
+// releaseHold освобождает зарезервированную сумму и переводит резерв в статус status
+func (s *CardService) releaseHold(tx *gorm.DB, hold *models.PaymentHold, status string) error {
+	account, err := s.accountRepo.FindByIDWithLock(tx, hold.AccountID)
+	if err != nil {
+		return accountservice.ErrAccountNotFound
+	}
+
+	account.HoldAmount = account.HoldAmount.Sub(hold.Amount)
+	if account.HoldAmount.IsNegative() {
+		account.HoldAmount = decimal.Zero
+	}
+	if err := s.accountRepo.UpdateWithTx(tx, account); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	hold.Status = status
+	hold.ReleasedAt = &now
+	return s.holdRepo.UpdateWithTx(tx, hold)
+}
+
+func (s *CardService) notifyPayment(hold *models.PaymentHold, account *models.Account, transaction *models.Transaction) {
+	user, err := s.userRepo.FindByID(account.UserID)
+	if err != nil || user == nil {
+		return
+	}
+
+	masked := ""
+	card, err := s.cardRepo.FindByID(hold.CardID)
+	if err == nil && card != nil {
+		if number, err := s.decryptNumber(card.CardNumber, card.CardKeyID); err == nil {
+			masked = pan.Mask(number)
+		}
+	}
+
+	notification := dto.PaymentNotification{
+		To:         user.Email,
+		Name:       user.Username,
+		CardMasked: masked,
+		Amount:     transaction.Amount,
+		Balance:    account.Balance,
+		Date:       transaction.CreatedAt,
+	}
+	if err := s.mailService.SendPaymentSuccess(notification); err != nil {
+		s.log.Warning("Mail not found: " + err.Error())
+	}
+}
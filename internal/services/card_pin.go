@@ -0,0 +1,125 @@
+package services
+
+import (
+	"BankSystem/internal/models"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// maxPINAttempts — после стольких неверных попыток подряд карта блокируется
+const maxPINAttempts = 3
+
+var (
+	ErrPINNotSet        = errors.New("card PIN is not set")
+	ErrPINAlreadySet    = errors.New("card PIN is already set, use PIN change")
+	ErrIncorrectPIN     = errors.New("incorrect PIN")
+	ErrPINTriesExceeded = errors.New("PIN tries exceeded, card is blocked")
+)
+
+// SetPIN устанавливает PIN-код карты, у которой его ещё нет
+func (s *CardService) SetPIN(cardID uint, userID uint, pin string) error {
+	if err := s.checkOwner(cardID, userID); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	err = s.cardRepo.WithinTransaction(func(tx *gorm.DB) error {
+		card, err := s.cardRepo.FindByIDWithLock(tx, cardID)
+		if err != nil {
+			return ErrCardNotFound
+		}
+		if card.HasPIN() {
+			return ErrPINAlreadySet
+		}
+		pinHash := string(hash)
+		card.PinHash = &pinHash
+		card.PinAttempts = 0
+		return s.cardRepo.UpdateWithTx(tx, card)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("PIN set for card " + strconv.Itoa(int(cardID)))
+	return nil
+}
+
+// ChangePIN меняет PIN-код после проверки текущего. Неверный текущий PIN засчитывается как неудачная попытка.
+func (s *CardService) ChangePIN(cardID uint, userID uint, oldPIN string, newPIN string) error {
+	if err := s.checkOwner(cardID, userID); err != nil {
+		return err
+	}
+	if err := s.verifyPIN(cardID, oldPIN); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPIN), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	err = s.cardRepo.WithinTransaction(func(tx *gorm.DB) error {
+		card, err := s.cardRepo.FindByIDWithLock(tx, cardID)
+		if err != nil {
+			return ErrCardNotFound
+		}
+		pinHash := string(hash)
+		card.PinHash = &pinHash
+		return s.cardRepo.UpdateWithTx(tx, card)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("PIN changed for card " + strconv.Itoa(int(cardID)))
+	return nil
+}
+
+// verifyPIN сверяет PIN-код и ведёт счётчик неверных попыток. После maxPINAttempts неверных попыток подряд
+// карта блокируется; счётчик сбрасывается верным PIN или разблокировкой карты владельцем.
+// Счётчик сохраняется в отдельной транзакции, чтобы откат вызывающей операции его не отменял.
+func (s *CardService) verifyPIN(cardID uint, pin string) error {
+	var result error
+	err := s.cardRepo.WithinTransaction(func(tx *gorm.DB) error {
+		card, err := s.cardRepo.FindByIDWithLock(tx, cardID)
+		if err != nil {
+			return ErrCardNotFound
+		}
+		if !card.HasPIN() {
+			result = ErrPINNotSet
+			return nil
+		}
+		if card.PinAttempts >= maxPINAttempts {
+			result = ErrPINTriesExceeded
+			return nil
+		}
+
+		if bcrypt.CompareHashAndPassword([]byte(*card.PinHash), []byte(pin)) == nil {
+			if card.PinAttempts == 0 {
+				return nil
+			}
+			card.PinAttempts = 0
+			return s.cardRepo.UpdateWithTx(tx, card)
+		}
+
+		card.PinAttempts++
+		result = ErrIncorrectPIN
+		if card.PinAttempts >= maxPINAttempts {
+			now := time.Now().UTC()
+			card.Status = models.CardStatusBlocked
+			card.StatusChangedAt = &now
+			result = ErrPINTriesExceeded
+			s.log.Warning("card " + strconv.Itoa(int(card.ID)) + " blocked after " + strconv.Itoa(maxPINAttempts) + " wrong PIN attempts")
+		}
+		return s.cardRepo.UpdateWithTx(tx, card)
+	})
+	if err != nil {
+		return err
+	}
+	return result
+}
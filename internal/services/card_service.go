@@ -1,32 +1,71 @@
 package services
 
 import (
+	"BankSystem/internal/config"
 	"BankSystem/internal/dto"
 	"BankSystem/internal/models"
 	"BankSystem/internal/repositories"
+	"BankSystem/internal/security"
 	"BankSystem/internal/utils"
+	"BankSystem/internal/utils/pan"
 	"errors"
 	"fmt"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
-	"math/rand"
 	"strconv"
 	"time"
 
 	accountservice "BankSystem/internal/services/account"
 )
 
+var (
+	ErrCardNotFound                = errors.New("card not found")
+	ErrCardBlocked                 = errors.New("card is blocked")
+	ErrCardLost                    = errors.New("card is reported as lost")
+	ErrCardExpired                 = errors.New("card is expired")
+	ErrCardClosed                  = errors.New("card is closed")
+	ErrInvalidCardStatusTransition = errors.New("card status transition is not allowed")
+	ErrCardNotReissuable           = errors.New("card has already been reissued")
+	ErrInvalidCardLimits           = errors.New("limits must not exceed the limits of a longer period")
+	ErrInvalidCardOptions          = errors.New("single use, spending cap and custom expiry are available for virtual cards only")
+	ErrInvalidCardExpiry           = errors.New("card expiry must be in the future and within the maximum card term")
+	ErrCardProductNotFound         = errors.New("card product not found")
+	ErrCardNumberRangeExhausted    = errors.New("could not generate a unique card number")
+)
+
+// maxCardTerm — максимальный срок действия карты, в том числе заданный для виртуальной карты
+const maxCardTerm = 5 * 365 * 24 * time.Hour
+
+// CardOptions — параметры выпускаемой карты
+type CardOptions struct {
+	// ProductCode — код карточного продукта; пустой — продукт по умолчанию
+	ProductCode string
+	Kind        string
+	SingleUse   bool
+	SpendingCap *decimal.Decimal
+	// ExpiresAt — срок действия виртуальной карты; nil — стандартный срок
+	ExpiresAt *time.Time
+}
+
 type CardService struct {
-	db             *gorm.DB
-	cardRepo       *repositories.CardRepository
-	accountRepo    *repositories.AccountRepository
-	userRepo       *repositories.UserRepository
-	accountService *accountservice.AccountService
-	mailService    *MailService
-	encryptKey     string
-	log            *logrus.Logger
+	db              *gorm.DB
+	cardRepo        *repositories.CardRepository
+	accountRepo     *repositories.AccountRepository
+	userRepo        *repositories.UserRepository
+	transactionRepo *repositories.TransactionRepository
+	holdRepo        *repositories.PaymentHoldRepository
+	merchantRepo    *repositories.MerchantRepository
+	productRepo     *repositories.CardProductRepository
+	tokenRepo       *repositories.CardTokenRepository
+	accountService  *accountservice.AccountService
+	mailService     *MailService
+	keyring         *security.Keyring
+	legacyKey       string
+	indexKey        string
+	cfg             config.CardConfig
+	log             *logrus.Logger
 }
 
 func NewCardService(
@@ -34,19 +73,35 @@ func NewCardService(
 	cardRepo *repositories.CardRepository,
 	accountRepo *repositories.AccountRepository,
 	userRepo *repositories.UserRepository,
+	transactionRepo *repositories.TransactionRepository,
+	holdRepo *repositories.PaymentHoldRepository,
+	merchantRepo *repositories.MerchantRepository,
+	productRepo *repositories.CardProductRepository,
+	tokenRepo *repositories.CardTokenRepository,
 	accountService *accountservice.AccountService,
 	mailService *MailService,
-	encryptKey string,
+	keyring *security.Keyring,
+	legacyKey string,
+	indexKey string,
+	cfg config.CardConfig,
 	log *logrus.Logger) *CardService {
 	return &CardService{
-		db:             db,
-		cardRepo:       cardRepo,
-		accountRepo:    accountRepo,
-		userRepo:       userRepo,
-		accountService: accountService,
-		mailService:    mailService,
-		encryptKey:     encryptKey,
-		log:            log,
+		db:              db,
+		cardRepo:        cardRepo,
+		accountRepo:     accountRepo,
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		holdRepo:        holdRepo,
+		merchantRepo:    merchantRepo,
+		productRepo:     productRepo,
+		tokenRepo:       tokenRepo,
+		accountService:  accountService,
+		mailService:     mailService,
+		keyring:         keyring,
+		legacyKey:       legacyKey,
+		indexKey:        indexKey,
+		cfg:             cfg,
+		log:             log,
 	}
 }
 
@@ -56,47 +111,58 @@ func (s *CardService) GetCardsByUserID(userID uint) ([]dto.CardResponse, error)
 		return nil, errors.New("error getting card by user id")
 	}
 
+	monthStart := startOfMonth(time.Now().UTC())
 	for i := range dtos {
-		decryptedNumber, err := s.decryptWithPGP(dtos[i].Number, s.encryptKey)
+		spent, err := s.spentSince(dtos[i].ID, monthStart)
+		if err != nil {
+			return nil, errors.New("error getting card spending")
+		}
+		dtos[i].SpentThisMonth = spent
+
+		decryptedNumber, err := s.decryptNumber(dtos[i].Number, dtos[i].KeyID)
 		if err != nil {
 			accountId := strconv.Itoa(int(dtos[i].AccountID))
-			decryptedNumber = "**** **** **** "
+			decryptedNumber = ""
 			logrus.Error("failed to decrypt card number for account: " + accountId + ", error: " + err.Error())
 		}
 
-		dtos[i].Number = decryptedNumber
+		// Полный номер показывается только при выпуске карты
+		dtos[i].Number = pan.Mask(decryptedNumber)
+		if dtos[i].PaymentSystem == "" {
+			dtos[i].PaymentSystem = pan.Scheme(decryptedNumber)
+		}
 	}
 	return dtos, nil
 }
 
-func (s *CardService) GenerateCard(accountID uint) (*models.Card, error) {
-	id, err := s.cardRepo.FindByAccountId(accountID)
-	if err != nil || id != nil {
-		logrus.Warn("a card has already been created for this accountId " + strconv.Itoa(int(accountID)))
-		return nil, errors.New("a card has already been created for this account")
+// GenerateCard выпускает карту на аккаунт. У аккаунта может быть несколько физических и виртуальных карт.
+func (s *CardService) GenerateCard(accountID uint, opts CardOptions) (*models.Card, error) {
+	if opts.Kind == "" {
+		opts.Kind = models.CardKindPhysical
+	}
+	if opts.Kind != models.CardKindVirtual && (opts.SingleUse || opts.SpendingCap != nil || opts.ExpiresAt != nil) {
+		return nil, ErrInvalidCardOptions
 	}
 
-	cardNumber := utils.GenerateLuhnNumber(16)
-	cvv := fmt.Sprintf("%03d", rand.Intn(999)+1)
-	expiry := time.Now().AddDate(0, 5, 0).AddDate(0, rand.Intn(24), 0)
-
-	// Шифруем номер карты
-	encryptedNumber, err := s.encryptWithPGP(cardNumber, s.encryptKey)
+	product, err := s.resolveProduct(opts.ProductCode)
 	if err != nil {
 		return nil, err
 	}
 
-	// Хэшируем CVV
-	hashedCVV, err := s.hashCVV(cvv)
+	card, cardNumber, cvv, err := s.newCard(accountID, product)
 	if err != nil {
 		return nil, err
 	}
-
-	card := &models.Card{
-		AccountId:  accountID,
-		CardNumber: encryptedNumber,
-		Cvv:        hashedCVV,
-		ExpiredAt:  expiry,
+	card.Kind = opts.Kind
+	card.SingleUse = opts.SingleUse
+	card.SpendingCap = opts.SpendingCap
+	if opts.ExpiresAt != nil {
+		now := time.Now().UTC()
+		expiry := opts.ExpiresAt.UTC()
+		if !expiry.After(now) || expiry.After(now.Add(maxCardTerm)) {
+			return nil, ErrInvalidCardExpiry
+		}
+		card.ExpiredAt = expiry
 	}
 
 	err = s.cardRepo.Create(card)
@@ -106,105 +172,500 @@ func (s *CardService) GenerateCard(accountID uint) (*models.Card, error) {
 	card.CardNumber = cardNumber
 	card.Cvv = cvv
 
-	logrus.Info("created new card for account " + strconv.Itoa(int(accountID)))
+	logrus.Info("created new " + card.Kind + " card for account " + strconv.Itoa(int(accountID)))
 	return card, nil
 }
 
-// encryptWithPGP шифрует данные с помощью PGP через SQL-функцию
-func (s *CardService) encryptWithPGP(data string, key string) (string, error) {
-	var encrypted string
-	query := `SELECT encode(pgp_sym_encrypt($1::text, $2::text), 'hex')`
-	err := s.db.Raw(query, data, key).Scan(&encrypted).Error
-	if err != nil {
+// ChangeStatus — блокировка, разблокировка или отметка об утере карты владельцем, возвращает прежний статус
+func (s *CardService) ChangeStatus(cardID uint, userID uint, status string) (string, error) {
+	if err := s.checkOwner(cardID, userID); err != nil {
 		return "", err
 	}
-	return encrypted, nil
-}
 
-// decryptWithPGP расшифровывает данные с помощью PGP через SQL-функцию
-func (s *CardService) decryptWithPGP(encryptedHex string, key string) (string, error) {
-	var decrypted string
-	query := `SELECT pgp_sym_decrypt(decode($1::text, 'hex'), $2::text)`
-	err := s.db.Raw(query, encryptedHex, key).Scan(&decrypted).Error
+	var previous string
+	err := s.cardRepo.WithinTransaction(func(tx *gorm.DB) error {
+		card, err := s.cardRepo.FindByIDWithLock(tx, cardID)
+		if err != nil {
+			return ErrCardNotFound
+		}
+		if !card.CanTransitTo(status) {
+			return ErrInvalidCardStatusTransition
+		}
+
+		now := time.Now().UTC()
+		previous = card.Status
+		card.Status = status
+		card.StatusChangedAt = &now
+		// Разблокировка владельцем снимает и блокировку за неверный PIN
+		if status == models.CardStatusActive {
+			card.PinAttempts = 0
+		}
+		return s.cardRepo.UpdateWithTx(tx, card)
+	})
 	if err != nil {
 		return "", err
 	}
-	return decrypted, nil
+
+	logrus.Info("card " + strconv.Itoa(int(cardID)) + " status changed from " + previous + " to " + status)
+	return previous, nil
 }
 
-// hashCVV создает bcrypt-хеш для CVV
-func (s *CardService) hashCVV(cvv string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(cvv), bcrypt.DefaultCost)
+// Reissue выпускает на тот же аккаунт карту с новым номером и CVV взамен указанной.
+// Новая карта получает вид и лимиты прежней. Прежняя карта закрывается, утерянная остаётся в статусе lost.
+// Каждую карту можно перевыпустить только один раз.
+// Возвращает новую карту с открытыми номером и CVV.
+func (s *CardService) Reissue(cardID uint, userID uint) (*models.Card, error) {
+	if err := s.checkOwner(cardID, userID); err != nil {
+		return nil, err
+	}
+
+	var card *models.Card
+	var cardNumber, cvv string
+	err := s.cardRepo.WithinTransaction(func(tx *gorm.DB) error {
+		old, err := s.cardRepo.FindByIDWithLock(tx, cardID)
+		if err != nil {
+			return ErrCardNotFound
+		}
+		if !old.CanReissue() {
+			return ErrCardNotReissuable
+		}
+		// Карта заблокирована, поэтому параллельный перевыпуск дождётся этой транзакции и увидит замену
+		replaced, err := s.cardRepo.HasSuccessorWithTx(tx, old.ID)
+		if err != nil {
+			return err
+		}
+		if replaced {
+			return ErrCardNotReissuable
+		}
+
+		product, err := s.reissueProduct(old)
+		if err != nil {
+			return err
+		}
+		card, cardNumber, cvv, err = s.newCard(old.AccountId, product)
+		if err != nil {
+			return err
+		}
+		card.ReissuedFromID = &old.ID
+		card.Kind = old.Kind
+		card.SingleUse = old.SingleUse
+		card.SpendingCap = old.SpendingCap
+		card.PerTransactionLimit = old.PerTransactionLimit
+		card.DailyLimit = old.DailyLimit
+		card.MonthlyLimit = old.MonthlyLimit
+		card.HourlyPaymentsLimit = old.HourlyPaymentsLimit
+
+		now := time.Now().UTC()
+		if old.Status != models.CardStatusLost {
+			old.Status = models.CardStatusClosed
+		}
+		old.StatusChangedAt = &now
+		if err := s.cardRepo.UpdateWithTx(tx, old); err != nil {
+			return err
+		}
+		if err := s.cardRepo.CreateWithTx(tx, card); err != nil {
+			return err
+		}
+		// Мерчанты продолжают списывать по выданным токенам уже с новой карты
+		return s.tokenRepo.MoveToCardWithTx(tx, old.ID, card.ID)
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return string(hash), nil
+	card.CardNumber = cardNumber
+	card.Cvv = cvv
+
+	logrus.Info("card " + strconv.Itoa(int(cardID)) + " reissued as card " + strconv.Itoa(int(card.ID)))
+	return card, nil
 }
 
-// validateCVV проверяет CVV против хеша
-func (s *CardService) validateCVV(cvv string, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(cvv))
-	return err == nil
+// CardLimits — лимиты карты, nil означает отсутствие ограничения
+type CardLimits struct {
+	PerTransaction  *decimal.Decimal
+	Daily           *decimal.Decimal
+	Monthly         *decimal.Decimal
+	PaymentsPerHour *int
 }
 
-func (s *CardService) PayWithCard(req dto.CardPaymentRequest) (decimal.Decimal, error) {
-	card, err := s.cardRepo.FindByPlainCardNumber(req.CardNumber, s.encryptKey)
-	if err != nil || card == nil {
-		return decimal.Zero, errors.New("card not found")
+// SetLimits заменяет лимиты карты владельца
+func (s *CardService) SetLimits(cardID uint, userID uint, limits CardLimits) (*models.Card, error) {
+	if err := validateLimits(limits); err != nil {
+		return nil, err
+	}
+
+	card, err := s.cardRepo.FindByIDAndUserID(cardID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, ErrCardNotFound
 	}
 
-	isValidCVV := s.validateCVV(req.Cvv, card.Cvv)
-	if !isValidCVV {
-		return decimal.Zero, errors.New("CVV is not valid")
+	card.PerTransactionLimit = limits.PerTransaction
+	card.DailyLimit = limits.Daily
+	card.MonthlyLimit = limits.Monthly
+	card.HourlyPaymentsLimit = limits.PaymentsPerHour
+	if err := s.cardRepo.UpdateLimits(card); err != nil {
+		return nil, err
 	}
 
-	account, err := s.accountRepo.FindByID(card.AccountId)
+	logrus.Info("card " + strconv.Itoa(int(cardID)) + " limits updated")
+	return card, nil
+}
+
+// GetLimits возвращает лимиты карты и текущее использование
+func (s *CardService) GetLimits(cardID uint, userID uint) (*dto.CardLimitsResponse, error) {
+	card, err := s.cardRepo.FindByIDAndUserID(cardID, userID)
 	if err != nil {
-		return decimal.Zero, errors.New("card account not found")
+		return nil, err
+	}
+	if card == nil {
+		return nil, ErrCardNotFound
 	}
 
-	withdraw, err := s.accountService.Withdraw(account.ID, account.UserID, decimal.NewFromFloat(req.Amount))
+	now := time.Now().UTC()
+	spentToday, err := s.spentSince(card.ID, startOfDay(now))
 	if err != nil {
-		return decimal.Zero, err
+		return nil, err
+	}
+	spentThisMonth, err := s.spentSince(card.ID, startOfMonth(now))
+	if err != nil {
+		return nil, err
+	}
+	paymentsLastHour, err := s.paymentsSince(card.ID, now.Add(-time.Hour))
+	if err != nil {
+		return nil, err
 	}
 
-	user, err := s.userRepo.FindByID(account.UserID)
+	return &dto.CardLimitsResponse{
+		CardID:           card.ID,
+		PerTransaction:   card.PerTransactionLimit,
+		Daily:            card.DailyLimit,
+		Monthly:          card.MonthlyLimit,
+		PaymentsPerHour:  card.HourlyPaymentsLimit,
+		SpentToday:       spentToday,
+		SpentThisMonth:   spentThisMonth,
+		PaymentsLastHour: paymentsLastHour,
+	}, nil
+}
+
+// spentSince — списанные оплаты по карте и ещё не списанные резервы начиная с since
+func (s *CardService) spentSince(cardID uint, since time.Time) (decimal.Decimal, error) {
+	captured, err := s.transactionRepo.SumCardPayments(cardID, since)
 	if err != nil {
 		return decimal.Zero, err
 	}
+	authorized, err := s.holdRepo.SumAuthorized(cardID, since)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return captured.Add(authorized), nil
+}
+
+// paymentsSince — количество оплат и резервов по карте начиная с since
+func (s *CardService) paymentsSince(cardID uint, since time.Time) (int64, error) {
+	captured, err := s.transactionRepo.CountCardPayments(cardID, since)
+	if err != nil {
+		return 0, err
+	}
+	authorized, err := s.holdRepo.CountAuthorized(cardID, since)
+	if err != nil {
+		return 0, err
+	}
+	return captured + authorized, nil
+}
 
-	transaction := &models.Transaction{
-		FromAccountID:   account.ID,
-		ToAccountID:     account.ID,
-		Amount:          decimal.NewFromFloat(req.Amount),
-		TransactionType: "payment",
-		Currency:        "RUB",
+func validateLimits(limits CardLimits) error {
+	if limits.PerTransaction != nil && limits.Daily != nil && limits.PerTransaction.GreaterThan(*limits.Daily) {
+		return ErrInvalidCardLimits
+	}
+	if limits.Daily != nil && limits.Monthly != nil && limits.Daily.GreaterThan(*limits.Monthly) {
+		return ErrInvalidCardLimits
 	}
-	err = s.db.Create(transaction).Error
+	if limits.PerTransaction != nil && limits.Monthly != nil && limits.PerTransaction.GreaterThan(*limits.Monthly) {
+		return ErrInvalidCardLimits
+	}
+	return nil
+}
 
+// ExpireCards помечает истёкшими карты, срок действия которых закончился
+func (s *CardService) ExpireCards() (int64, error) {
+	count, err := s.cardRepo.MarkExpired(time.Now().UTC())
 	if err != nil {
-		return decimal.Zero, err
+		return 0, err
 	}
+	if count > 0 {
+		s.log.Info("marked " + strconv.Itoa(int(count)) + " cards as expired")
+	}
+	return count, nil
+}
+
+// maxPANAttempts — сколько раз генерируется номер, прежде чем признать диапазон BIN исчерпанным
+const maxPANAttempts = 10
 
-	notification := dto.PaymentNotification{
-		To:        user.Email,
-		Name:      user.Username,
-		CardLast4: getLast4Digits(card.CardNumber),
-		Amount:    transaction.Amount,
-		Balance:   withdraw,
-		Date:      transaction.CreatedAt,
+// newCard генерирует номер в диапазоне BIN продукта, CVV и срок действия. Возвращает карту с зашифрованным номером
+// и хешем CVV для сохранения, а также открытые номер и CVV для показа владельцу.
+func (s *CardService) newCard(accountID uint, product *models.CardProduct) (*models.Card, string, string, error) {
+	cardNumber, numberIndex, err := s.reservePAN(product)
+	if err != nil {
+		return nil, "", "", err
 	}
-	err = s.mailService.SendPaymentSuccess(notification)
+	cvv, err := utils.RandomDigits(3)
 	if err != nil {
-		logrus.Warningf("Mail not found: " + err.Error())
+		return nil, "", "", err
 	}
+	// Карта действует до конца месяца, указанного на ней
+	now := time.Now().UTC()
+	expiry := time.Date(now.Year(), now.Month()+time.Month(product.ValidityMonths)+1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Second)
 
-	return withdraw, nil
+	// Шифруем номер карты
+	encryptedNumber, keyID, err := s.keyring.Encrypt(cardNumber)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	// Хэшируем CVV
+	hashedCVV, err := s.hashCVV(cvv)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	card := &models.Card{
+		AccountId:       accountID,
+		ProductID:       &product.ID,
+		CardNumber:      encryptedNumber,
+		CardKeyID:       &keyID,
+		CardNumberIndex: &numberIndex,
+		Cvv:             hashedCVV,
+		ExpiredAt:       expiry,
+		Status:          models.CardStatusActive,
+		Kind:            models.CardKindPhysical,
+	}
+	return card, cardNumber, cvv, nil
+}
+
+// reservePAN генерирует номер, ещё не выданный ни одной карте. Уникальность окончательно гарантирует
+// уникальный индекс card_number_index при сохранении. Возвращает номер и его blind index.
+func (s *CardService) reservePAN(product *models.CardProduct) (string, string, error) {
+	for attempt := 0; attempt < maxPANAttempts; attempt++ {
+		number, err := utils.GenerateLuhnNumber(product.BIN, product.PANLength)
+		if err != nil {
+			return "", "", err
+		}
+		index := s.numberIndex(number)
+		exists, err := s.cardRepo.ExistsByNumberIndex(index)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			return number, index, nil
+		}
+	}
+	s.log.Error("could not generate unique card number for product " + product.Code)
+	return "", "", ErrCardNumberRangeExhausted
+}
+
+// resolveProduct — активный продукт по коду, по умолчанию CARD_DEFAULT_PRODUCT
+func (s *CardService) resolveProduct(code string) (*models.CardProduct, error) {
+	if code == "" {
+		code = s.cfg.DefaultProduct
+	}
+	product, err := s.productRepo.FindActiveByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrCardProductNotFound
+	}
+	return product, nil
+}
+
+// reissueProduct — продукт перевыпускаемой карты; если он отключён или не задан, используется продукт по умолчанию
+func (s *CardService) reissueProduct(card *models.Card) (*models.CardProduct, error) {
+	if card.ProductID != nil {
+		product, err := s.productRepo.FindByID(*card.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if product != nil && product.Active {
+			return product, nil
+		}
+	}
+	return s.resolveProduct("")
+}
+
+// FindByNumber ищет карту по blind index и сверяет расшифрованный номер, чтобы исключить коллизию индекса.
+// Возвращает nil, если карта не найдена.
+func (s *CardService) FindByNumber(number string) (*models.Card, error) {
+	card, err := s.cardRepo.FindByNumberIndex(s.numberIndex(number))
+	if err != nil || card == nil {
+		return nil, err
+	}
+	decrypted, err := s.decryptNumber(card.CardNumber, card.CardKeyID)
+	if err != nil {
+		return nil, err
+	}
+	if decrypted != number {
+		return nil, nil
+	}
+	return card, nil
+}
+
+// GetTransactions — операции по карте текущего пользователя, новые первыми
+func (s *CardService) GetTransactions(cardID uint, userID uint, limit int, offset int) ([]models.Transaction, error) {
+	if err := s.checkOwner(cardID, userID); err != nil {
+		return nil, err
+	}
+	return s.transactionRepo.FindByCardID(cardID, limit, offset)
+}
+
+// GetProducts — продукты, доступные для выпуска карт
+func (s *CardService) GetProducts() ([]models.CardProduct, error) {
+	return s.productRepo.FindAllActive()
+}
+
+func (s *CardService) checkOwner(cardID uint, userID uint) error {
+	card, err := s.cardRepo.FindByIDAndUserID(cardID, userID)
+	if err != nil {
+		return err
+	}
+	if card == nil {
+		return ErrCardNotFound
+	}
+	return nil
+}
+
+// BackfillNumberIndex вычисляет blind index для карт, у которых он ещё не заполнен
+func (s *CardService) BackfillNumberIndex() (int64, error) {
+	count, err := s.cardRepo.BackfillNumberIndex(s.legacyKey, s.indexKey)
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		s.log.Info("backfilled number index for " + strconv.Itoa(int(count)) + " cards")
+	}
+	return count, nil
+}
+
+// numberIndex — blind index номера карты
+func (s *CardService) numberIndex(cardNumber string) string {
+	return utils.BlindIndex(cardNumber, s.indexKey)
+}
+
+// reencryptBatchSize — количество карт, перешифровываемых за один запрос к БД
+const reencryptBatchSize = 100
+
+// ReencryptCards перешифровывает активным ключом все карты, зашифрованные другими ключами или pgcrypto.
+// Возвращает количество перешифрованных карт.
+func (s *CardService) ReencryptCards() (int, error) {
+	activeID := s.keyring.ActiveKeyID()
+	reencrypted := 0
+	var afterID uint
+	for {
+		cards, err := s.cardRepo.FindNotEncryptedWith(activeID, afterID, reencryptBatchSize)
+		if err != nil {
+			return reencrypted, err
+		}
+		if len(cards) == 0 {
+			break
+		}
+
+		for i := range cards {
+			card := &cards[i]
+			afterID = card.ID
+
+			number, err := s.decryptNumber(card.CardNumber, card.CardKeyID)
+			if err != nil {
+				return reencrypted, fmt.Errorf("card %d: %w", card.ID, err)
+			}
+			encryptedNumber, keyID, err := s.keyring.Encrypt(number)
+			if err != nil {
+				return reencrypted, err
+			}
+			updated, err := s.cardRepo.UpdateEncryption(card, encryptedNumber, keyID)
+			if err != nil {
+				return reencrypted, err
+			}
+			if updated {
+				reencrypted++
+			}
+		}
+	}
+
+	s.log.Info("reencrypted " + strconv.Itoa(reencrypted) + " cards with key " + activeID)
+	return reencrypted, nil
+}
+
+// ReindexCards пересчитывает blind index всех карт текущим ключом индекса, например после смены CARD_INDEX_KEY.
+// Возвращает количество карт, индекс которых изменился.
+func (s *CardService) ReindexCards() (int, error) {
+	reindexed := 0
+	var afterID uint
+	for {
+		cards, err := s.cardRepo.FindAfterID(afterID, reencryptBatchSize)
+		if err != nil {
+			return reindexed, err
+		}
+		if len(cards) == 0 {
+			break
+		}
+
+		for i := range cards {
+			card := &cards[i]
+			afterID = card.ID
+
+			number, err := s.decryptNumber(card.CardNumber, card.CardKeyID)
+			if err != nil {
+				return reindexed, fmt.Errorf("card %d: %w", card.ID, err)
+			}
+			index := s.numberIndex(number)
+			if card.CardNumberIndex != nil && *card.CardNumberIndex == index {
+				continue
+			}
+			updated, err := s.cardRepo.UpdateNumberIndex(card, index)
+			if err != nil {
+				return reindexed, err
+			}
+			if updated {
+				reindexed++
+			}
+		}
+	}
+
+	s.log.Info("reindexed " + strconv.Itoa(reindexed) + " cards")
+	return reindexed, nil
+}
+
+// decryptNumber расшифровывает номер карты ключом из набора, а номера без ключа — устаревшим ключом pgcrypto
+func (s *CardService) decryptNumber(encrypted string, keyID *string) (string, error) {
+	if keyID == nil {
+		return s.decryptWithPGP(encrypted, s.legacyKey)
+	}
+	return s.keyring.Decrypt(encrypted, *keyID)
+}
+
+// decryptWithPGP расшифровывает данные, зашифрованные PGP до перехода на набор ключей
+func (s *CardService) decryptWithPGP(encryptedHex string, key string) (string, error) {
+	var decrypted string
+	query := `SELECT pgp_sym_decrypt(decode($1::text, 'hex'), $2::text)`
+	err := s.db.Raw(query, encryptedHex, key).Scan(&decrypted).Error
+	if err != nil {
+		return "", err
+	}
+	return decrypted, nil
 }
 
-func getLast4Digits(s string) string {
-	if len(s) < 4 {
-		return ""
+// hashCVV создает bcrypt-хеш для CVV
+func (s *CardService) hashCVV(cvv string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(cvv), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
 	}
-	return s[len(s)-4:]
+	return string(hash), nil
+}
+
+// validateCVV проверяет CVV против хеша
+func (s *CardService) validateCVV(cvv string, hash string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(cvv))
+	return err == nil
 }
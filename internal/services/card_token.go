@@ -0,0 +1,120 @@
+package services
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/security"
+	"BankSystem/internal/utils/pan"
+	"errors"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrCardTokenNotFound  = errors.New("card token not found")
+	ErrHoldNotTokenizable = errors.New("only a captured CVV-verified merchant payment can be tokenized")
+)
+
+// IssueToken выдаёт токен карты владельцу мерчанта по списанной оплате, прошедшей проверку CVV.
+// Резерв без списания можно отменить, поэтому по нему токен не выдаётся.
+// Открытое значение токена возвращается один раз, в БД хранится только его хеш.
+func (s *CardService) IssueToken(hold *models.PaymentHold, userID uint) (string, *models.CardToken, error) {
+	if hold.MerchantID == nil || hold.TokenID != nil || hold.Status != models.HoldStatusCaptured {
+		return "", nil, ErrHoldNotTokenizable
+	}
+	merchant, err := s.merchantRepo.FindByIDAndOwnerID(*hold.MerchantID, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if merchant == nil {
+		return "", nil, ErrMerchantNotFound
+	}
+
+	card, err := s.cardRepo.FindByID(hold.CardID)
+	if err != nil {
+		return "", nil, err
+	}
+	if card == nil {
+		return "", nil, ErrCardNotFound
+	}
+	number, err := s.decryptNumber(card.CardNumber, card.CardKeyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	value, hash, err := security.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	token := &models.CardToken{
+		CardID:     card.ID,
+		MerchantID: *hold.MerchantID,
+		TokenHash:  hash,
+		Last4:      pan.Last4(number),
+		Status:     models.CardTokenStatusActive,
+	}
+	if err := s.tokenRepo.Create(token); err != nil {
+		return "", nil, err
+	}
+
+	s.log.Info("issued token " + strconv.Itoa(int(token.ID)) + " for card " + strconv.Itoa(int(card.ID)) + " to merchant " + strconv.Itoa(int(token.MerchantID)))
+	return value, token, nil
+}
+
+// PayWithToken — одноэтапная оплата, инициированная мерчантом по токену карты без CVV.
+// Проводить оплату может только владелец мерчанта, которому выдан токен.
+func (s *CardService) PayWithToken(req dto.TokenPaymentRequest, userID uint) (*models.PaymentHold, decimal.Decimal, error) {
+	merchant, err := s.merchantRepo.FindByIDAndOwnerID(req.MerchantID, userID)
+	if err != nil || merchant == nil {
+		return nil, decimal.Zero, decline(AuthCodeInvalidMerchant, ErrMerchantNotFound.Error())
+	}
+
+	auth, err := s.authorizeToken(merchant.ID, req.Token, req.Amount)
+	if err != nil {
+		return nil, decimal.Zero, err
+	}
+	hold, err := s.reserve(auth, userID, false)
+	if err != nil {
+		return nil, decimal.Zero, err
+	}
+	if err := s.tokenRepo.MarkUsed(auth.cardToken.ID, time.Now().UTC()); err != nil {
+		s.log.Warning("could not mark token " + strconv.Itoa(int(auth.cardToken.ID)) + " as used: " + err.Error())
+	}
+	return s.payNow(hold)
+}
+
+// GetTokens — токены карты, выданные мерчантам
+func (s *CardService) GetTokens(cardID uint, userID uint) ([]models.CardToken, error) {
+	if err := s.checkOwner(cardID, userID); err != nil {
+		return nil, err
+	}
+	return s.tokenRepo.FindAllByCardID(cardID)
+}
+
+// RevokeToken отзывает токен карты, после чего мерчант не может проводить по нему оплаты
+func (s *CardService) RevokeToken(cardID uint, tokenID uint, userID uint) (*models.CardToken, error) {
+	if err := s.checkOwner(cardID, userID); err != nil {
+		return nil, err
+	}
+	token, err := s.tokenRepo.FindByIDAndCardID(tokenID, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ErrCardTokenNotFound
+	}
+	if token.Status == models.CardTokenStatusRevoked {
+		return token, nil
+	}
+
+	now := time.Now().UTC()
+	token.Status = models.CardTokenStatusRevoked
+	token.RevokedAt = &now
+	if err := s.tokenRepo.Update(token); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("card token " + strconv.Itoa(int(token.ID)) + " revoked")
+	return token, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/utils/pan"
+	"errors"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+
+	accountservice "BankSystem/internal/services/account"
+)
+
+var (
+	ErrRecipientCardNotFound    = errors.New("recipient card not found")
+	ErrRecipientCardUnavailable = errors.New("recipient card can not accept transfers")
+)
+
+// CardTransfer — результат перевода с карты на карту
+type CardTransfer struct {
+	CardID          uint
+	RecipientCardID uint
+	Amount          decimal.Decimal
+	Balance         decimal.Decimal
+}
+
+// transferPipeline — проверки перевода с карты на карту: карта отправителя проверяется как при оплате,
+// включая её лимиты, но без мерчанта, затем ищется карта получателя
+func (s *CardService) transferPipeline() []authorizationStep {
+	return []authorizationStep{
+		s.checkPAN,
+		s.checkExpiry,
+		s.checkCVV,
+		s.checkCardStatus,
+		s.checkAccountStatus,
+		s.checkCardOwner,
+		s.checkLimits,
+		s.checkBalance,
+		s.checkRecipient,
+	}
+}
+
+// TransferByCard переводит деньги с карты текущего пользователя на карту получателя по её номеру.
+// Деньги переводятся между аккаунтами карт тем же путём, что и /transfer/create, обе стороны получают уведомление.
+// При отказе возвращает *AuthorizationError.
+func (s *CardService) TransferByCard(req dto.CardTransferRequest, userID uint) (*CardTransfer, error) {
+	a := &cardAuthorization{
+		req: dto.CardPaymentRequest{
+			CardNumber: req.CardNumber,
+			Cvv:        req.Cvv,
+			Amount:     req.Amount,
+			ExpiredAt:  req.ExpiredAt,
+		},
+		amount:          decimal.NewFromFloat(req.Amount),
+		now:             time.Now(),
+		userID:          userID,
+		recipientNumber: req.RecipientCardNumber,
+	}
+	if err := runPipeline(a, s.transferPipeline()); err != nil {
+		return nil, err
+	}
+
+	cardID := a.card.ID
+	description := "Card transfer to " + pan.Mask(a.recipientNumber)
+	details := accountservice.TransferDetails{CardID: &cardID, Description: &description}
+	var sender, recipient *models.Account
+	err := s.accountRepo.WithinTransaction(func(tx *gorm.DB) error {
+		// Аккаунты блокируются раньше карты, как при оплате; лимиты перепроверяются под блокировкой карты
+		if _, _, err := s.accountService.LockPair(tx, a.account.ID, a.recipientCard.AccountId); err != nil {
+			return err
+		}
+		card, err := s.cardRepo.FindByIDWithLock(tx, cardID)
+		if err != nil {
+			return decline(AuthCodeInvalidCard, "card not found")
+		}
+		a.card = card
+		if err := s.checkCardStatus(a); err != nil {
+			return err
+		}
+		if err := s.checkLimits(a); err != nil {
+			return err
+		}
+
+		sender, recipient, err = s.accountService.TransferWithTx(tx, a.account.ID, a.recipientCard.AccountId, a.amount, details)
+		if err != nil {
+			return err
+		}
+		return s.closeSingleUse(tx, cardID, time.Now().UTC())
+	})
+	if err != nil {
+		return nil, debitError(err)
+	}
+
+	s.notifyTransfer(a, sender, recipient)
+	s.log.Info("card transfer of " + a.amount.StringFixed(2) + " from card " + strconv.Itoa(int(cardID)) + " to card " + strconv.Itoa(int(a.recipientCard.ID)))
+	return &CardTransfer{
+		CardID:          cardID,
+		RecipientCardID: a.recipientCard.ID,
+		Amount:          a.amount,
+		Balance:         sender.Balance,
+	}, nil
+}
+
+// checkCardOwner — переводить деньги можно только с карты аккаунта текущего пользователя
+func (s *CardService) checkCardOwner(a *cardAuthorization) error {
+	if a.account.UserID != a.userID {
+		return decline(AuthCodeInvalidCard, "card not found")
+	}
+	return nil
+}
+
+// checkRecipient ищет карту получателя по blind index. Зачислять можно только на действующую карту
+// с аккаунтом, принимающим зачисления, и отличным от аккаунта отправителя.
+func (s *CardService) checkRecipient(a *cardAuthorization) error {
+	card, err := s.FindByNumber(a.recipientNumber)
+	if err != nil || card == nil {
+		return decline(AuthCodeInvalidCard, ErrRecipientCardNotFound.Error())
+	}
+	if card.Status != models.CardStatusActive || !a.now.Before(card.ExpiredAt) {
+		return decline(AuthCodeNotPermitted, ErrRecipientCardUnavailable.Error())
+	}
+	if card.AccountId == a.account.ID {
+		return decline(AuthCodeNotPermitted, accountservice.ErrSameAccount.Error())
+	}
+
+	account, err := s.accountRepo.FindByID(card.AccountId)
+	if err != nil || account == nil || !account.CanCredit() {
+		return decline(AuthCodeNotPermitted, ErrRecipientCardUnavailable.Error())
+	}
+	if account.Currency != a.account.Currency {
+		return decline(AuthCodeNotPermitted, "recipient card account has a different currency")
+	}
+	a.recipientCard = card
+	return nil
+}
+
+// notifyTransfer уведомляет отправителя о списании, а получателя — о зачислении
+func (s *CardService) notifyTransfer(a *cardAuthorization, sender *models.Account, recipient *models.Account) {
+	now := time.Now()
+	senderMasked := pan.Mask(a.req.CardNumber)
+	recipientMasked := pan.Mask(a.recipientNumber)
+
+	s.sendTransferNotification(sender.UserID, dto.CardTransferNotification{
+		CardMasked:         senderMasked,
+		CounterpartyMasked: recipientMasked,
+		Amount:             a.amount,
+		Balance:            sender.Balance,
+		Date:               now,
+	})
+	s.sendTransferNotification(recipient.UserID, dto.CardTransferNotification{
+		Incoming:           true,
+		CardMasked:         recipientMasked,
+		CounterpartyMasked: senderMasked,
+		Amount:             a.amount,
+		Balance:            recipient.Balance,
+		Date:               now,
+	})
+}
+
+func (s *CardService) sendTransferNotification(userID uint, notification dto.CardTransferNotification) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return
+	}
+	notification.To = user.Email
+	notification.Name = user.Username
+	if err := s.mailService.SendCardTransfer(notification); err != nil {
+		s.log.Warning("could not send card transfer notification to user " + strconv.Itoa(int(userID)) + ": " + err.Error())
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"BankSystem/internal/config"
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/repositories"
+	"BankSystem/internal/security"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrLoginLocked        = errors.New("too many failed login attempts, access is temporarily locked")
+	ErrLoginThrottled     = errors.New("too many failed login attempts, retry later")
+	ErrInvalidUnlockToken = errors.New("unlock link is invalid or expired")
+)
+
+// LoginBlockedError — попытка входа отклонена до проверки пароля, RetryAfter — когда можно повторить
+type LoginBlockedError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return e.Err
+}
+
+type LoginProtectionService struct {
+	throttleRepo *repositories.LoginThrottleRepository
+	tokenRepo    *repositories.UserTokenRepository
+	auditService *AuditService
+	mailService  *MailService
+	cfg          config.LoginProtectionConfig
+	appBaseURL   string
+	log          *logrus.Logger
+}
+
+func NewLoginProtectionService(
+	throttleRepo *repositories.LoginThrottleRepository,
+	tokenRepo *repositories.UserTokenRepository,
+	auditService *AuditService,
+	mailService *MailService,
+	cfg config.AuthConfig,
+	log *logrus.Logger) *LoginProtectionService {
+	return &LoginProtectionService{
+		throttleRepo: throttleRepo,
+		tokenRepo:    tokenRepo,
+		auditService: auditService,
+		mailService:  mailService,
+		cfg:          cfg.LoginProtection,
+		appBaseURL:   cfg.AppBaseURL,
+		log:          log,
+	}
+}
+
+// LoginAttempt — попытка входа, учтённая до проверки пароля, и значения счётчиков после неё
+type LoginAttempt struct {
+	ipAttempts      int
+	accountAttempts int
+}
+
+// Reserve проверяет, разрешена ли сейчас попытка входа для аккаунта (если он найден) и IP-адреса,
+// и сразу учитывает её как неудачную. Строки счётчиков блокируются на время проверки, поэтому
+// параллельные попытки не проходят задержку одновременно.
+func (s *LoginProtectionService) Reserve(user *models.User, ip string) (*LoginAttempt, error) {
+	now := time.Now().UTC()
+	windowStart := now.Add(-s.cfg.FailureWindow)
+	attempt := &LoginAttempt{}
+
+	err := s.throttleRepo.WithinTransaction(func(tx *gorm.DB) error {
+		for _, target := range s.throttleKeys(user, ip) {
+			throttle, err := s.throttleRepo.FindOrCreateWithLock(tx, target.scope, target.key, now)
+			if err != nil {
+				return err
+			}
+			if err := s.checkThrottle(throttle, now); err != nil {
+				return err
+			}
+
+			attempts, err := s.throttleRepo.ReserveAttemptWithTx(tx, throttle, now, windowStart)
+			if err != nil {
+				return err
+			}
+			if target.scope == models.ThrottleScopeIP {
+				attempt.ipAttempts = attempts
+			} else {
+				attempt.accountAttempts = attempts
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// RegisterFailure блокирует вход, если неудачная попытка превысила порог
+func (s *LoginProtectionService) RegisterFailure(user *models.User, attempt *LoginAttempt, actx AuditContext) error {
+	now := time.Now().UTC()
+	if attempt.ipAttempts >= s.cfg.IPMaxAttempts {
+		if err := s.lockIP(actx, now); err != nil {
+			return err
+		}
+	}
+
+	if user != nil && attempt.accountAttempts >= s.cfg.AccountMaxAttempts {
+		return s.lockAccount(user, actx, now)
+	}
+	return nil
+}
+
+// RegisterSuccess сбрасывает счётчик аккаунта и возвращает попытку, учтённую для IP.
+// Остальные неудачи IP не сбрасываются, чтобы успешный вход в свой аккаунт
+// не открывал перебор чужих с того же адреса.
+func (s *LoginProtectionService) RegisterSuccess(user *models.User, ip string) error {
+	if err := s.throttleRepo.ReleaseAttempt(models.ThrottleScopeIP, ip); err != nil {
+		return err
+	}
+	return s.throttleRepo.Reset(models.ThrottleScopeAccount, strconv.Itoa(int(user.ID)))
+}
+
+// Unlock снимает блокировку аккаунта по одноразовой ссылке из письма
+func (s *LoginProtectionService) Unlock(token string, actx AuditContext) error {
+	unlockToken, err := s.tokenRepo.FindActiveByHash(security.HashOpaqueToken(token), models.TokenPurposeAccountUnlock)
+	if err != nil {
+		return err
+	}
+	if unlockToken == nil {
+		return ErrInvalidUnlockToken
+	}
+
+	err = s.tokenRepo.WithinTransaction(func(tx *gorm.DB) error {
+		marked, err := s.tokenRepo.MarkUsedWithTx(tx, unlockToken.ID)
+		if err != nil {
+			return err
+		}
+		if !marked {
+			return ErrInvalidUnlockToken
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	accountKey := strconv.Itoa(int(unlockToken.UserID))
+	if err := s.throttleRepo.Reset(models.ThrottleScopeAccount, accountKey); err != nil {
+		return err
+	}
+
+	actx.ActorID = &unlockToken.UserID
+	s.auditService.TryRecord(AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionAccountUnlock,
+		EntityType:   models.AuditEntityUser,
+		EntityID:     accountKey,
+	})
+	s.log.Info("login unlocked by email for user " + accountKey)
+	return nil
+}
+
+func (s *LoginProtectionService) lockIP(actx AuditContext, now time.Time) error {
+	ip := actx.IP
+	lockedUntil := now.Add(s.cfg.LockoutDuration)
+	if err := s.throttleRepo.Lock(models.ThrottleScopeIP, ip, lockedUntil); err != nil {
+		return err
+	}
+
+	s.auditService.TryRecord(AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionIPLockout,
+		EntityType:   models.AuditEntityIP,
+		EntityID:     ip,
+		Details: map[string]interface{}{
+			"locked_until": lockedUntil,
+			"max_attempts": s.cfg.IPMaxAttempts,
+		},
+	})
+	s.log.Warn("login locked for ip " + ip)
+	return nil
+}
+
+func (s *LoginProtectionService) lockAccount(user *models.User, actx AuditContext, now time.Time) error {
+	accountKey := strconv.Itoa(int(user.ID))
+	lockedUntil := now.Add(s.cfg.LockoutDuration)
+	if err := s.throttleRepo.Lock(models.ThrottleScopeAccount, accountKey, lockedUntil); err != nil {
+		return err
+	}
+
+	s.auditService.TryRecord(AuditEntry{
+		AuditContext: actx,
+		Action:       models.AuditActionLoginLockout,
+		EntityType:   models.AuditEntityUser,
+		EntityID:     accountKey,
+		Details: map[string]interface{}{
+			"locked_until": lockedUntil,
+			"max_attempts": s.cfg.AccountMaxAttempts,
+		},
+	})
+	s.log.Warn("login locked for user " + accountKey)
+
+	return s.sendUnlockLink(user, lockedUntil)
+}
+
+func (s *LoginProtectionService) sendUnlockLink(user *models.User, lockedUntil time.Time) error {
+	token, hash, err := security.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokenRepo.InvalidateForUser(user.ID, models.TokenPurposeAccountUnlock); err != nil {
+		return err
+	}
+
+	err = s.tokenRepo.Create(&models.UserToken{
+		UserID:    user.ID,
+		Purpose:   models.TokenPurposeAccountUnlock,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.cfg.UnlockTokenTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	notification := dto.AccountLockedNotification{
+		To:          user.Email,
+		Name:        user.Username,
+		Link:        s.appBaseURL + "/auth/unlock?token=" + url.QueryEscape(token),
+		LockedUntil: lockedUntil.Local(),
+	}
+	if err := s.mailService.SendAccountLocked(notification); err != nil {
+		s.log.Warning("unlock mail not sent: " + err.Error())
+	}
+	return nil
+}
+
+type throttleTarget struct {
+	scope string
+	key   string
+}
+
+// throttleKeys — счётчики попытки в постоянном порядке: сначала IP, затем аккаунт,
+// чтобы параллельные транзакции блокировали строки без взаимоблокировок
+func (s *LoginProtectionService) throttleKeys(user *models.User, ip string) []throttleTarget {
+	targets := []throttleTarget{{scope: models.ThrottleScopeIP, key: ip}}
+	if user != nil {
+		targets = append(targets, throttleTarget{scope: models.ThrottleScopeAccount, key: strconv.Itoa(int(user.ID))})
+	}
+	return targets
+}
+
+// checkThrottle отклоняет попытку, если вход заблокирован или не истекла задержка после прошлых неудач
+func (s *LoginProtectionService) checkThrottle(throttle *models.LoginThrottle, now time.Time) error {
+	if throttle.LockedUntil != nil && now.Before(*throttle.LockedUntil) {
+		return &LoginBlockedError{Err: ErrLoginLocked, RetryAfter: throttle.LockedUntil.Sub(now)}
+	}
+
+	if throttle.LastFailedAt != nil {
+		nextAttemptAt := throttle.LastFailedAt.Add(s.delayFor(throttle.FailedAttempts))
+		if now.Before(nextAttemptAt) {
+			return &LoginBlockedError{Err: ErrLoginThrottled, RetryAfter: nextAttemptAt.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// delayFor — прогрессивная задержка перед следующей попыткой после attempts неудач подряд
+func (s *LoginProtectionService) delayFor(attempts int) time.Duration {
+	if attempts < s.cfg.DelayAfterAttempts {
+		return 0
+	}
+	delay := s.cfg.BaseDelay
+	for i := s.cfg.DelayAfterAttempts; i < attempts && delay < s.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.cfg.MaxDelay {
+		delay = s.cfg.MaxDelay
+	}
+	return delay
+}
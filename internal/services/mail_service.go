@@ -5,6 +5,7 @@ import (
 	"context"
 	"github.com/mailgun/mailgun-go/v4"
 	"github.com/sirupsen/logrus"
+	"strconv"
 	"time"
 )
 
@@ -23,20 +24,58 @@ func NewMailService(apiKey, domain string, log *logrus.Logger) *MailService {
 }
 
 func (s *MailService) SendPaymentSuccess(data dto.PaymentNotification) error {
+	return s.send(data.To, "Платёж успешно выполнен", s.buildHTML(data))
+}
+
+// SendPasswordReset отправляет письмо со ссылкой для сброса пароля
+func (s *MailService) SendPasswordReset(data dto.PasswordResetNotification) error {
+	return s.send(data.To, "Восстановление пароля", s.buildPasswordResetHTML(data))
+}
+
+// SendEmailVerification отправляет письмо со ссылкой для подтверждения email
+func (s *MailService) SendEmailVerification(data dto.EmailVerificationNotification) error {
+	return s.send(data.To, "Подтверждение email", s.buildEmailVerificationHTML(data))
+}
+
+// SendAccountLocked уведомляет о блокировке входа и отправляет ссылку для разблокировки
+func (s *MailService) SendAccountLocked(data dto.AccountLockedNotification) error {
+	return s.send(data.To, "Вход в аккаунт временно заблокирован", s.buildAccountLockedHTML(data))
+}
+
+// SendPaymentOTP отправляет одноразовый код для подтверждения оплаты картой
+func (s *MailService) SendPaymentOTP(data dto.PaymentOTPNotification) error {
+	return s.send(data.To, "Код подтверждения оплаты", s.buildPaymentOTPHTML(data))
+}
+
+// SendCardTransfer уведомляет отправителя или получателя о переводе с карты на карту
+func (s *MailService) SendCardTransfer(data dto.CardTransferNotification) error {
+	subject := "Перевод с карты выполнен"
+	if data.Incoming {
+		subject = "Зачисление перевода на карту"
+	}
+	return s.send(data.To, subject, s.buildCardTransferHTML(data))
+}
+
+// SendStandingOrderFailed уведомляет, что перевод по регулярному поручению не выполнен
+func (s *MailService) SendStandingOrderFailed(data dto.StandingOrderFailedNotification) error {
+	return s.send(data.To, "Регулярный перевод не выполнен", s.buildStandingOrderFailedHTML(data))
+}
+
+func (s *MailService) send(to, subject, html string) error {
 	message := s.mg.NewMessage(
 		"noreply@yourbank.com",
-		"Платёж успешно выполнен",
-		s.buildHTML(data),
-		data.To,
+		subject,
+		html,
+		to,
 	)
 
-	logrus.Info("Send email: ", s.buildHTML(data))
+	logrus.Info("Send email: ", subject, " to ", to)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 
 	_, _, err := s.mg.Send(ctx, message)
 	if err != nil {
-		logrus.WithError(err).WithField("email", data.To).Error("Failed to send email")
+		logrus.WithError(err).WithField("email", to).Error("Failed to send email")
 		return err
 	}
 
@@ -47,10 +86,93 @@ func (s *MailService) buildHTML(data dto.PaymentNotification) string {
 	return `
         <h2>Платёж успешно выполнен</h2>
         <p>Здравствуйте, ` + data.Name + `</p>
-        <p>С карты <strong>**** **** **** ` + data.CardLast4 + `</strong> списано <strong>` + data.Amount.StringFixed(2) + ` RUB</strong></p>
+        <p>С карты <strong>` + data.CardMasked + `</strong> списано <strong>` + data.Amount.StringFixed(2) + ` RUB</strong></p>
+        <p>Новый баланс: ` + data.Balance.StringFixed(2) + ` RUB</p>
+        <p>Дата: ` + data.Date.Format("02.01.2006 15:04") + `</p>
+        <hr/>
+        <p><small>© BankSystem - Ваш банк доверяет Go</small></p>
+    `
+}
+
+func (s *MailService) buildPasswordResetHTML(data dto.PasswordResetNotification) string {
+	return `
+        <h2>Восстановление пароля</h2>
+        <p>Здравствуйте, ` + data.Name + `</p>
+        <p>Чтобы задать новый пароль, перейдите по <a href="` + data.Link + `">ссылке</a>.</p>
+        <p>Ссылка одноразовая и действует до ` + data.ExpiresAt.Format("02.01.2006 15:04") + `.</p>
+        <p>Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.</p>
+        <hr/>
+        <p><small>© BankSystem - Ваш банк доверяет Go</small></p>
+    `
+}
+
+func (s *MailService) buildEmailVerificationHTML(data dto.EmailVerificationNotification) string {
+	return `
+        <h2>Подтверждение email</h2>
+        <p>Здравствуйте, ` + data.Name + `</p>
+        <p>Для завершения регистрации подтвердите email, перейдя по <a href="` + data.Link + `">ссылке</a>.</p>
+        <p>Ссылка действует до ` + data.ExpiresAt.Format("02.01.2006 15:04") + `.</p>
+        <p>До подтверждения email операции с деньгами недоступны.</p>
+        <hr/>
+        <p><small>© BankSystem - Ваш банк доверяет Go</small></p>
+    `
+}
+
+func (s *MailService) buildPaymentOTPHTML(data dto.PaymentOTPNotification) string {
+	return `
+        <h2>Подтверждение оплаты</h2>
+        <p>Здравствуйте, ` + data.Name + `</p>
+        <p>Для оплаты <strong>` + data.Amount.StringFixed(2) + ` RUB</strong> картой <strong>` + data.CardMasked + `</strong> введите код: <strong>` + data.Code + `</strong></p>
+        <p>Код действует до ` + data.ExpiresAt.Format("02.01.2006 15:04") + `. Никому его не сообщайте.</p>
+        <p>Если вы не совершали оплату, заблокируйте карту.</p>
+        <hr/>
+        <p><small>© BankSystem - Ваш банк доверяет Go</small></p>
+    `
+}
+
+func (s *MailService) buildCardTransferHTML(data dto.CardTransferNotification) string {
+	operation := `<p>С карты <strong>` + data.CardMasked + `</strong> переведено <strong>` + data.Amount.StringFixed(2) + ` RUB</strong> на карту ` + data.CounterpartyMasked + `</p>`
+	if data.Incoming {
+		operation = `<p>На карту <strong>` + data.CardMasked + `</strong> зачислено <strong>` + data.Amount.StringFixed(2) + ` RUB</strong> с карты ` + data.CounterpartyMasked + `</p>`
+	}
+	return `
+        <h2>Перевод с карты на карту</h2>
+        <p>Здравствуйте, ` + data.Name + `</p>
+        ` + operation + `
         <p>Новый баланс: ` + data.Balance.StringFixed(2) + ` RUB</p>
         <p>Дата: ` + data.Date.Format("02.01.2006 15:04") + `</p>
         <hr/>
         <p><small>© BankSystem - Ваш банк доверяет Go</small></p>
     `
 }
+
+func (s *MailService) buildStandingOrderFailedHTML(data dto.StandingOrderFailedNotification) string {
+	outcome := ""
+	switch {
+	case data.Cancelled:
+		outcome = `<p>Поручение отменено, переводы по нему больше не выполняются.</p>`
+	case data.NextAttemptAt != nil:
+		outcome = `<p>Следующая попытка: ` + data.NextAttemptAt.Format("02.01.2006 15:04") + `</p>`
+	}
+	return `
+        <h2>Регулярный перевод не выполнен</h2>
+        <p>Здравствуйте, ` + data.Name + `</p>
+        <p>Перевод <strong>` + data.Amount.StringFixed(2) + ` RUB</strong> на аккаунт ` + strconv.Itoa(int(data.ToAccountID)) + ` по поручению №` + strconv.Itoa(int(data.OrderID)) + ` не выполнен: ` + data.Reason + `</p>
+        ` + outcome + `
+        <p>Дата: ` + data.Date.Format("02.01.2006 15:04") + `</p>
+        <hr/>
+        <p><small>© BankSystem - Ваш банк доверяет Go</small></p>
+    `
+}
+
+func (s *MailService) buildAccountLockedHTML(data dto.AccountLockedNotification) string {
+	return `
+        <h2>Вход в аккаунт временно заблокирован</h2>
+        <p>Здравствуйте, ` + data.Name + `</p>
+        <p>Мы зафиксировали несколько неудачных попыток входа в ваш аккаунт и заблокировали вход до ` + data.LockedUntil.Format("02.01.2006 15:04") + `.</p>
+        <p>Если это были вы, разблокируйте вход по <a href="` + data.Link + `">ссылке</a>.</p>
+        <p>Если нет, рекомендуем сменить пароль.</p>
+        <hr/>
+        <p><small>© BankSystem - Ваш банк доверяет Go</small></p>
+    `
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/repositories"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"strconv"
+)
+
+var ErrInvalidSettlementAccount = errors.New("settlement account not found or can not accept payments")
+
+type MerchantService struct {
+	merchantRepo *repositories.MerchantRepository
+	accountRepo  *repositories.AccountRepository
+	holdRepo     *repositories.PaymentHoldRepository
+	log          *logrus.Logger
+}
+
+func NewMerchantService(
+	merchantRepo *repositories.MerchantRepository,
+	accountRepo *repositories.AccountRepository,
+	holdRepo *repositories.PaymentHoldRepository,
+	log *logrus.Logger) *MerchantService {
+	return &MerchantService{
+		merchantRepo: merchantRepo,
+		accountRepo:  accountRepo,
+		holdRepo:     holdRepo,
+		log:          log,
+	}
+}
+
+// Create регистрирует мерчанта. Расчётным может быть только собственный аккаунт пользователя.
+func (s *MerchantService) Create(ownerID uint, req dto.CreateMerchantRequest) (*models.Merchant, error) {
+	account, err := s.accountRepo.FindByIdAndUserID(req.SettlementAccountID, ownerID)
+	if err != nil || account == nil || !account.CanCredit() {
+		return nil, ErrInvalidSettlementAccount
+	}
+
+	merchant := &models.Merchant{
+		OwnerUserID:         ownerID,
+		Name:                req.Name,
+		SettlementAccountID: account.ID,
+	}
+	if err := s.merchantRepo.Create(merchant); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("created merchant " + strconv.Itoa(int(merchant.ID)) + " for user " + strconv.Itoa(int(ownerID)))
+	return merchant, nil
+}
+
+func (s *MerchantService) GetByOwnerID(ownerID uint) ([]models.Merchant, error) {
+	return s.merchantRepo.FindAllByOwnerID(ownerID)
+}
+
+// GetPayments — оплаты мерчанта, доступны только его владельцу
+func (s *MerchantService) GetPayments(merchantID uint, ownerID uint, limit int, offset int) ([]models.PaymentHold, error) {
+	merchant, err := s.merchantRepo.FindByIDAndOwnerID(merchantID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if merchant == nil {
+		return nil, ErrMerchantNotFound
+	}
+	return s.holdRepo.FindByMerchantID(merchant.ID, limit, offset)
+}
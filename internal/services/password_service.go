@@ -0,0 +1,129 @@
+package services
+
+import (
+	"BankSystem/internal/config"
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/repositories"
+	"BankSystem/internal/security"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidResetToken = errors.New("reset token is invalid or expired")
+
+type PasswordService struct {
+	userRepo    *repositories.UserRepository
+	tokenRepo   *repositories.UserTokenRepository
+	mailService *MailService
+	cfg         config.AuthConfig
+	log         *logrus.Logger
+}
+
+func NewPasswordService(
+	userRepo *repositories.UserRepository,
+	tokenRepo *repositories.UserTokenRepository,
+	mailService *MailService,
+	cfg config.AuthConfig,
+	log *logrus.Logger) *PasswordService {
+	return &PasswordService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		mailService: mailService,
+		cfg:         cfg,
+		log:         log,
+	}
+}
+
+// RequestReset выпускает одноразовый токен сброса и отправляет ссылку на почту.
+// Для несуществующего email ошибка не возвращается, чтобы не раскрывать наличие пользователя.
+func (s *PasswordService) RequestReset(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		s.log.Warn("password reset requested for unknown email " + email)
+		return nil
+	}
+
+	token, hash, err := security.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	// Предыдущие ссылки перестают работать после запроса новой
+	if err := s.tokenRepo.InvalidateForUser(user.ID, models.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.cfg.PasswordResetTTL)
+	err = s.tokenRepo.Create(&models.UserToken{
+		UserID:    user.ID,
+		Purpose:   models.TokenPurposePasswordReset,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	notification := dto.PasswordResetNotification{
+		To:        user.Email,
+		Name:      user.Username,
+		Link:      s.cfg.AppBaseURL + "/auth/password/reset?token=" + url.QueryEscape(token),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.mailService.SendPasswordReset(notification); err != nil {
+		s.log.Warning("password reset mail not sent: " + err.Error())
+	}
+
+	s.log.Info("password reset requested for user " + strconv.Itoa(int(user.ID)))
+	return nil
+}
+
+// ResetPassword меняет пароль по токену из письма. Токен гасится, а выданные ранее JWT
+// перестают приниматься, так как выпущены до password_changed_at.
+// Возвращает ID пользователя, сменившего пароль.
+func (s *PasswordService) ResetPassword(token string, newPassword string) (uint, error) {
+	resetToken, err := s.tokenRepo.FindActiveByHash(security.HashOpaqueToken(token), models.TokenPurposePasswordReset)
+	if err != nil {
+		return 0, err
+	}
+	if resetToken == nil {
+		return 0, ErrInvalidResetToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.tokenRepo.WithinTransaction(func(tx *gorm.DB) error {
+		marked, err := s.tokenRepo.MarkUsedWithTx(tx, resetToken.ID)
+		if err != nil {
+			return err
+		}
+		if !marked {
+			return ErrInvalidResetToken
+		}
+
+		if err := s.tokenRepo.InvalidateForUserWithTx(tx, resetToken.UserID, models.TokenPurposePasswordReset); err != nil {
+			return err
+		}
+
+		changedAt := time.Now().UTC().Truncate(time.Second)
+		return s.userRepo.UpdatePasswordWithTx(tx, resetToken.UserID, string(hashedPassword), changedAt)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.log.Info("password has been reset for user " + strconv.Itoa(int(resetToken.UserID)))
+	return resetToken.UserID, nil
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"BankSystem/internal/config"
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/repositories"
+	"BankSystem/internal/utils/schedule"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+
+	accountservice "BankSystem/internal/services/account"
+)
+
+// standingOrdersBatch — сколько наступивших поручений выбирается за один запрос
+const standingOrdersBatch = 100
+
+var (
+	ErrStandingOrderNotFound  = errors.New("standing order not found")
+	ErrStandingOrderNotActive = errors.New("standing order is not active")
+	ErrInvalidOrderRecipient  = errors.New("recipient account not found or can not accept transfers")
+	ErrInvalidOrderStart      = errors.New("start date must not be in the past")
+	ErrInvalidOrderEnd        = errors.New("end date must be after the first scheduled transfer")
+)
+
+// StandingOrderService — регулярные переводы по расписанию
+type StandingOrderService struct {
+	orderRepo      *repositories.StandingOrderRepository
+	accountRepo    *repositories.AccountRepository
+	userRepo       *repositories.UserRepository
+	accountService *accountservice.AccountService
+	mailService    *MailService
+	cfg            config.StandingOrderConfig
+	log            *logrus.Logger
+}
+
+func NewStandingOrderService(
+	orderRepo *repositories.StandingOrderRepository,
+	accountRepo *repositories.AccountRepository,
+	userRepo *repositories.UserRepository,
+	accountService *accountservice.AccountService,
+	mailService *MailService,
+	cfg config.StandingOrderConfig,
+	log *logrus.Logger) *StandingOrderService {
+	return &StandingOrderService{
+		orderRepo:      orderRepo,
+		accountRepo:    accountRepo,
+		userRepo:       userRepo,
+		accountService: accountService,
+		mailService:    mailService,
+		cfg:            cfg,
+		log:            log,
+	}
+}
+
+// Create создаёт поручение. Списывать можно только с собственного аккаунта, первый перевод — не раньше текущей минуты.
+func (s *StandingOrderService) Create(userID uint, req dto.CreateStandingOrderRequest) (*models.StandingOrder, error) {
+	if req.FromAccountID == req.ToAccountID {
+		return nil, accountservice.ErrSameAccount
+	}
+	from, err := s.accountRepo.FindByIdAndUserID(req.FromAccountID, userID)
+	if err != nil || from == nil {
+		return nil, accountservice.ErrAccountNotFound
+	}
+	if err := accountservice.CheckDebit(from); err != nil {
+		return nil, err
+	}
+	to, err := s.accountRepo.FindByID(req.ToAccountID)
+	if err != nil || to == nil || !to.CanCredit() {
+		return nil, ErrInvalidOrderRecipient
+	}
+
+	start := req.StartAt.UTC().Truncate(time.Minute)
+	if start.Before(time.Now().UTC().Truncate(time.Minute)) {
+		return nil, ErrInvalidOrderStart
+	}
+	sched, err := schedule.Parse(req.Recurrence, start)
+	if err != nil {
+		return nil, err
+	}
+	first := schedule.First(sched, start)
+	if first.IsZero() {
+		return nil, schedule.ErrInvalidSchedule
+	}
+	var end *time.Time
+	if req.EndAt != nil {
+		endAt := req.EndAt.UTC()
+		if endAt.Before(first) {
+			return nil, ErrInvalidOrderEnd
+		}
+		end = &endAt
+	}
+
+	policy := req.OnInsufficientFunds
+	if policy == "" {
+		policy = models.OnInsufficientFundsSkip
+	}
+	order := &models.StandingOrder{
+		UserID:              userID,
+		FromAccountID:       from.ID,
+		ToAccountID:         to.ID,
+		Amount:              decimal.NewFromFloat(req.Amount),
+		Recurrence:          strings.TrimSpace(req.Recurrence),
+		StartAt:             start,
+		EndAt:               end,
+		OnInsufficientFunds: policy,
+		Status:              models.StandingOrderStatusActive,
+		NextRunAt:           &first,
+	}
+	if req.Description != "" {
+		order.Description = &req.Description
+	}
+	if err := s.orderRepo.Create(order); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("created standing order " + strconv.Itoa(int(order.ID)) + " for user " + strconv.Itoa(int(userID)))
+	return order, nil
+}
+
+func (s *StandingOrderService) GetByUserID(userID uint) ([]models.StandingOrder, error) {
+	return s.orderRepo.FindAllByUserID(userID)
+}
+
+// GetExecutions — история запусков поручения, доступна только его владельцу
+func (s *StandingOrderService) GetExecutions(orderID uint, userID uint, limit int, offset int) ([]models.StandingOrderExecution, error) {
+	order, err := s.orderRepo.FindByIDAndUserID(orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrStandingOrderNotFound
+	}
+	return s.orderRepo.FindExecutions(order.ID, limit, offset)
+}
+
+// Cancel отменяет активное поручение, дальнейшие переводы по нему не выполняются
+func (s *StandingOrderService) Cancel(orderID uint, userID uint) (*models.StandingOrder, error) {
+	var order *models.StandingOrder
+	err := s.orderRepo.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = s.orderRepo.FindByIDWithLock(tx, orderID)
+		if err != nil || order.UserID != userID {
+			return ErrStandingOrderNotFound
+		}
+		if order.Status != models.StandingOrderStatusActive {
+			return ErrStandingOrderNotActive
+		}
+		order.Status = models.StandingOrderStatusCancelled
+		order.NextRunAt = nil
+		order.RetryAt = nil
+		return s.orderRepo.UpdateWithTx(tx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("standing order " + strconv.Itoa(int(orderID)) + " cancelled")
+	return order, nil
+}
+
+// ExecuteDue исполняет наступившие поручения и возвращает число попыток перевода.
+// Если сервис был остановлен дольше одного периода, пропущенные запуски не догоняются: выполняется один перевод,
+// а следующий назначается по расписанию после текущего момента.
+func (s *StandingOrderService) ExecuteDue() (int, error) {
+	executed := 0
+	for {
+		ids, err := s.orderRepo.FindDueIDs(time.Now().UTC(), standingOrdersBatch)
+		if err != nil {
+			return executed, err
+		}
+
+		for _, id := range ids {
+			ran, err := s.execute(id)
+			if err != nil {
+				return executed, err
+			}
+			if ran {
+				executed++
+			}
+		}
+
+		if len(ids) < standingOrdersBatch {
+			break
+		}
+	}
+
+	if executed > 0 {
+		s.log.Info("executed " + strconv.Itoa(executed) + " standing orders")
+	}
+	return executed, nil
+}
+
+// execute выполняет перевод по поручению и записывает попытку в историю. Перевод, запись попытки и следующий запуск
+// фиксируются одной транзакцией, поэтому сбой между ними не приводит к повторному переводу.
+// Поручение заблокировано на время перевода, поэтому его нельзя отменить или исполнить повторно параллельно.
+func (s *StandingOrderService) execute(id uint) (bool, error) {
+	var order *models.StandingOrder
+	var execution *models.StandingOrderExecution
+	err := s.orderRepo.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = s.orderRepo.FindByIDWithLock(tx, id)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		// Поручение могли отменить или исполнить после выборки
+		due := order.DueAt()
+		if order.Status != models.StandingOrderStatusActive || due == nil || due.After(now) {
+			order = nil
+			return nil
+		}
+		sched, err := schedule.Parse(order.Recurrence, order.StartAt)
+		if err != nil {
+			return err
+		}
+
+		execution = &models.StandingOrderExecution{
+			StandingOrderID: order.ID,
+			ScheduledAt:     *order.NextRunAt,
+			Attempt:         order.Attempts + 1,
+			Amount:          order.Amount,
+			Status:          models.ExecutionStatusSucceeded,
+			CreatedAt:       now,
+		}
+		transferErr := s.transfer(tx, order)
+		if transferErr != nil {
+			reason := transferErr.Error()
+			execution.Error = &reason
+		}
+		s.applyOutcome(order, execution, sched, transferErr, now)
+
+		if err := s.orderRepo.CreateExecutionWithTx(tx, execution); err != nil {
+			return err
+		}
+		return s.orderRepo.UpdateWithTx(tx, order)
+	})
+	if err != nil || order == nil {
+		return false, err
+	}
+
+	if execution.Status != models.ExecutionStatusSucceeded {
+		s.log.Warning("standing order " + strconv.Itoa(int(order.ID)) + " transfer " + execution.Status + ": " + *execution.Error)
+		s.notifyFailure(order, execution)
+	}
+	return true, nil
+}
+
+// transfer переводит сумму поручения в транзакции tx с теми же проверками, что AccountService.Transfer:
+// аккаунт списания должен по-прежнему принадлежать владельцу поручения
+func (s *StandingOrderService) transfer(tx *gorm.DB, order *models.StandingOrder) error {
+	from, err := s.accountRepo.FindByIdAndUserID(order.FromAccountID, order.UserID)
+	if err != nil || from == nil {
+		return accountservice.ErrAccountNotFound
+	}
+	details := accountservice.TransferDetails{Description: order.Description}
+	_, _, err = s.accountService.TransferWithTx(tx, order.FromAccountID, order.ToAccountID, order.Amount, details)
+	return err
+}
+
+// applyOutcome обновляет поручение по результату перевода. Нехватка средств по политике retry повторяется
+// через RetryInterval, но не более MaxRetries раз и не позже следующего планового запуска, иначе запуск пропускается.
+// Закрытие или отсутствие аккаунта списания или получателя отменяет поручение, прочие ошибки пропускают запуск.
+func (s *StandingOrderService) applyOutcome(order *models.StandingOrder, execution *models.StandingOrderExecution, sched schedule.Schedule, err error, now time.Time) {
+	order.LastRunAt = &now
+	switch {
+	case err == nil:
+		s.advance(order, sched, now)
+	case errors.Is(err, accountservice.ErrInsufficientFunds):
+		retryAt := now.Add(s.cfg.RetryInterval)
+		next := sched.Next(*order.NextRunAt)
+		if order.OnInsufficientFunds == models.OnInsufficientFundsRetry &&
+			execution.Attempt <= s.cfg.MaxRetries &&
+			(next.IsZero() || retryAt.Before(next)) {
+			execution.Status = models.ExecutionStatusRetrying
+			order.Attempts = execution.Attempt
+			order.RetryAt = &retryAt
+			return
+		}
+		execution.Status = models.ExecutionStatusSkipped
+		s.advance(order, sched, now)
+	case errors.Is(err, accountservice.ErrRecipientAccountNotFound),
+		errors.Is(err, accountservice.ErrAccountNotFound),
+		errors.Is(err, accountservice.ErrAccountClosed):
+		execution.Status = models.ExecutionStatusFailed
+		order.Status = models.StandingOrderStatusCancelled
+		order.NextRunAt = nil
+		order.RetryAt = nil
+	default:
+		execution.Status = models.ExecutionStatusFailed
+		s.advance(order, sched, now)
+	}
+}
+
+// advance назначает следующий плановый запуск после now, а после даты окончания завершает поручение
+func (s *StandingOrderService) advance(order *models.StandingOrder, sched schedule.Schedule, now time.Time) {
+	order.Attempts = 0
+	order.RetryAt = nil
+
+	next := sched.Next(now)
+	if next.IsZero() || (order.EndAt != nil && next.After(*order.EndAt)) {
+		order.Status = models.StandingOrderStatusCompleted
+		order.NextRunAt = nil
+		return
+	}
+	order.NextRunAt = &next
+}
+
+func (s *StandingOrderService) notifyFailure(order *models.StandingOrder, execution *models.StandingOrderExecution) {
+	user, err := s.userRepo.FindByID(order.UserID)
+	if err != nil || user == nil {
+		return
+	}
+
+	notification := dto.StandingOrderFailedNotification{
+		To:          user.Email,
+		Name:        user.Username,
+		OrderID:     order.ID,
+		ToAccountID: order.ToAccountID,
+		Amount:      order.Amount,
+		Reason:      *execution.Error,
+		Cancelled:   order.Status == models.StandingOrderStatusCancelled,
+		Date:        execution.CreatedAt,
+	}
+	if order.Status == models.StandingOrderStatusActive {
+		notification.NextAttemptAt = order.DueAt()
+	}
+	if err := s.mailService.SendStandingOrderFailed(notification); err != nil {
+		s.log.Warning("could not send standing order failure notification for order " + strconv.Itoa(int(order.ID)) + ": " + err.Error())
+	}
+}
@@ -7,10 +7,25 @@ import (
 	"errors"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"strconv"
+	"strings"
 	"time"
 )
 
-var ErrUserNotFound = errors.New("user not found")
+var (
+	ErrUserNotFound          = errors.New("user not found")
+	ErrRecipientNotFound     = errors.New("recipient not found")
+	ErrRecipientNoAccount    = errors.New("recipient has no account that can accept transfers")
+	ErrInvalidDefaultAccount = errors.New("default account must be your own account that can accept transfers")
+)
+
+// Recipient — получатель перевода, найденный по username или email
+type Recipient struct {
+	UserID     uint
+	AccountID  uint
+	Currency   string
+	MaskedName string
+}
 
 type UserService struct {
 	userRepo       *repositories.UserRepository
@@ -31,6 +46,7 @@ func (s *UserService) RegisterUser(email, username, password string) error {
 		Email:    email,
 		Username: username,
 		Password: password,
+		Role:     models.RoleCustomer,
 	}
 	user.DeletedAt = gorm.DeletedAt{Valid: false, Time: time.Time{}}
 	logrus.Info("Create user " + username)
@@ -57,3 +73,92 @@ func (s *UserService) GetUserByUsername(username string) (*models.User, error) {
 	logrus.Info("Found user with username " + username + " not found")
 	return user, err
 }
+
+// GrantAdmin назначает роль администратора зарегистрированному пользователю.
+// Используется для создания первого администратора из командной строки, возвращает прежнюю роль.
+func (s *UserService) GrantAdmin(email string) (*models.User, string, error) {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, "", err
+	}
+	if user == nil {
+		return nil, "", ErrUserNotFound
+	}
+	previous := user.Role
+	if err := s.userRepo.UpdateRole(user.ID, models.RoleAdmin); err != nil {
+		return nil, "", err
+	}
+	user.Role = models.RoleAdmin
+	s.log.Info("granted admin role to user " + strconv.Itoa(int(user.ID)))
+	return user, previous, nil
+}
+
+// SetDefaultAccount назначает аккаунт, на который зачисляются переводы по username или email пользователя
+func (s *UserService) SetDefaultAccount(userID uint, accountID uint) error {
+	acc, err := s.accountService.GetByID(accountID, userID)
+	if err != nil || acc == nil || !acc.CanCredit() {
+		return ErrInvalidDefaultAccount
+	}
+	return s.userRepo.UpdateDefaultAccount(userID, acc.ID)
+}
+
+// ResolveRecipient ищет получателя перевода по email (алиас с @) или username.
+// Деньги зачисляются на аккаунт по умолчанию, а если он не задан или не принимает зачисления — на самый старый
+// аккаунт получателя, принимающий зачисления. Телефон станет алиасом, когда он появится у пользователей.
+func (s *UserService) ResolveRecipient(alias string) (*Recipient, error) {
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return nil, ErrRecipientNotFound
+	}
+
+	var user *models.User
+	var err error
+	if strings.Contains(alias, "@") {
+		user, err = s.userRepo.FindByEmail(alias)
+	} else {
+		user, err = s.userRepo.FindByUserName(alias)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrRecipientNotFound
+	}
+
+	accounts, err := s.accountService.GetAccountsByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var target *models.Account
+	for _, acc := range accounts {
+		if !acc.CanCredit() {
+			continue
+		}
+		if user.DefaultAccountID != nil && acc.ID == *user.DefaultAccountID {
+			target = acc
+			break
+		}
+		if target == nil {
+			target = acc
+		}
+	}
+	if target == nil {
+		return nil, ErrRecipientNoAccount
+	}
+
+	return &Recipient{
+		UserID:     user.ID,
+		AccountID:  target.ID,
+		Currency:   target.Currency,
+		MaskedName: maskName(user.Username),
+	}, nil
+}
+
+// maskName оставляет первый и последний символ имени: "ivanov" → "i****v"
+func maskName(name string) string {
+	runes := []rune(name)
+	if len(runes) <= 2 {
+		return string(runes[:1]) + "*"
+	}
+	return string(runes[0]) + strings.Repeat("*", len(runes)-2) + string(runes[len(runes)-1])
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"BankSystem/internal/config"
+	"BankSystem/internal/dto"
+	"BankSystem/internal/models"
+	"BankSystem/internal/repositories"
+	"BankSystem/internal/security"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const tokenPurposeEmailVerification = "email_verification"
+
+var (
+	ErrInvalidVerificationToken = errors.New("verification link is invalid or expired")
+	ErrVerificationThrottled    = errors.New("verification email was sent recently, try again later")
+)
+
+type VerificationService struct {
+	userRepo    *repositories.UserRepository
+	mailService *MailService
+	cfg         config.AuthConfig
+	secret      []byte
+	log         *logrus.Logger
+}
+
+func NewVerificationService(
+	userRepo *repositories.UserRepository,
+	mailService *MailService,
+	cfg config.AuthConfig,
+	secret string,
+	log *logrus.Logger) *VerificationService {
+	return &VerificationService{
+		userRepo:    userRepo,
+		mailService: mailService,
+		cfg:         cfg,
+		secret:      []byte(secret),
+		log:         log,
+	}
+}
+
+// SendVerification отправляет письмо с подписанной ссылкой подтверждения.
+// Возвращает ErrVerificationThrottled, если предыдущее письмо ушло недавно.
+func (s *VerificationService) SendVerification(user *models.User) error {
+	now := time.Now().UTC()
+	allowed, err := s.userRepo.TouchVerificationSent(user.ID, now, now.Add(-s.cfg.VerificationResendPeriod))
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrVerificationThrottled
+	}
+
+	token, err := security.SignPurposeToken(s.secret, tokenPurposeEmailVerification, user.ID, user.Email, s.cfg.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	notification := dto.EmailVerificationNotification{
+		To:        user.Email,
+		Name:      user.Username,
+		Link:      s.cfg.AppBaseURL + "/auth/verify?token=" + url.QueryEscape(token),
+		ExpiresAt: time.Now().Add(s.cfg.EmailVerificationTTL),
+	}
+	if err := s.mailService.SendEmailVerification(notification); err != nil {
+		s.log.Warning("verification mail not sent: " + err.Error())
+	}
+
+	s.log.Info("verification email sent to user " + strconv.Itoa(int(user.ID)))
+	return nil
+}
+
+// Resend повторно отправляет письмо подтверждения. Для неизвестных и уже подтверждённых
+// адресов ничего не делает, чтобы не раскрывать наличие пользователя.
+func (s *VerificationService) Resend(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.IsEmailVerified() {
+		return nil
+	}
+	return s.SendVerification(user)
+}
+
+// Verify подтверждает email по токену из ссылки. Токен, выпущенный для прежнего email, не принимается.
+func (s *VerificationService) Verify(token string) error {
+	claims, err := security.ParsePurposeToken(s.secret, tokenPurposeEmailVerification, token)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Email != claims.Subject {
+		return ErrInvalidVerificationToken
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	if err := s.userRepo.MarkEmailVerified(user.ID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	s.log.Info("email verified for user " + strconv.Itoa(int(user.ID)))
+	return nil
+}
@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// BlindIndex — HMAC-SHA256 от значения в hex. Позволяет искать зашифрованные данные по точному совпадению,
+// не расшифровывая их. Совпадает с encode(hmac(value, key, 'sha256'), 'hex') в pgcrypto.
+func BlindIndex(value string, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
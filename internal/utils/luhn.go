@@ -1,25 +1,56 @@
 package utils
 
 import (
-	"math/rand"
+	"crypto/rand"
+	"errors"
+	"math/big"
 	"strconv"
-	"time"
 )
 
-// GenerateLuhnNumber генерирует номер карты заданной длины, валидный по алгоритму Луна
-func GenerateLuhnNumber(length int) string {
-	source := rand.NewSource(time.Now().UnixNano())
-	r := rand.New(source)
+var ErrInvalidPrefix = errors.New("prefix must contain only digits and be shorter than the number")
 
-	number := make([]int, length-1)
-	for i := range number {
-		number[i] = r.Intn(10)
+// GenerateLuhnNumber генерирует криптографически случайный номер карты заданной длины, начинающийся с prefix (BIN).
+// Последняя цифра — контрольная по алгоритму Луна.
+func GenerateLuhnNumber(prefix string, length int) (string, error) {
+	if len(prefix) >= length || !isDigits(prefix) {
+		return "", ErrInvalidPrefix
 	}
 
+	random, err := RandomDigits(length - len(prefix) - 1)
+	if err != nil {
+		return "", err
+	}
+	number := prefix + random
+	return number + strconv.Itoa(luhnCheckDigit(number)), nil
+}
+
+// RandomDigits возвращает строку из n криптографически случайных цифр
+func RandomDigits(n int) (string, error) {
+	digits := make([]byte, n)
+	for i := range digits {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + d.Int64())
+	}
+	return string(digits), nil
+}
+
+// IsLuhnValid проверяет контрольную цифру номера
+func IsLuhnValid(number string) bool {
+	if len(number) < 2 || !isDigits(number) {
+		return false
+	}
+	return luhnCheckDigit(number[:len(number)-1]) == int(number[len(number)-1]-'0')
+}
+
+// luhnCheckDigit — контрольная цифра, которую нужно дописать к номеру
+func luhnCheckDigit(number string) int {
 	sum := 0
 	double := true
 	for i := len(number) - 1; i >= 0; i-- {
-		digit := number[i]
+		digit := int(number[i] - '0')
 		if double {
 			digit *= 2
 			if digit > 9 {
@@ -29,13 +60,14 @@ func GenerateLuhnNumber(length int) string {
 		sum += digit
 		double = !double
 	}
+	return (10 - (sum % 10)) % 10
+}
 
-	lastDigit := (10 - (sum % 10)) % 10
-	number = append(number, lastDigit)
-
-	var s string
-	for _, d := range number {
-		s += strconv.Itoa(d)
+func isDigits(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
 	}
-	return s
+	return true
 }
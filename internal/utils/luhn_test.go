@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsLuhnValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+		want   bool
+	}{
+		{"visa", "4111111111111111", true},
+		{"mastercard", "5555555555554444", true},
+		{"mir", "2200000000000004", true},
+		{"amex", "378282246310005", true},
+		{"wrong check digit", "4111111111111112", false},
+		{"transposed digits", "4111111111111141", false},
+		{"letters", "41111111111111a1", false},
+		{"too short", "4", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsLuhnValid(tt.number); got != tt.want {
+				t.Errorf("IsLuhnValid(%q) = %v, want %v", tt.number, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateLuhnNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		length int
+	}{
+		{"mir", "2200", 16},
+		{"visa", "4", 16},
+		{"long bin", "22041234", 19},
+		{"no prefix", "", 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				number, err := GenerateLuhnNumber(tt.prefix, tt.length)
+				if err != nil {
+					t.Fatalf("GenerateLuhnNumber(%q, %d) error: %v", tt.prefix, tt.length, err)
+				}
+				if len(number) != tt.length || !strings.HasPrefix(number, tt.prefix) || !IsLuhnValid(number) {
+					t.Fatalf("GenerateLuhnNumber(%q, %d) = %q", tt.prefix, tt.length, number)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateLuhnNumberInvalidPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		length int
+	}{
+		{"not digits", "22a0", 16},
+		{"prefix as long as number", "1234", 4},
+		{"prefix longer than number", "12345", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GenerateLuhnNumber(tt.prefix, tt.length); err != ErrInvalidPrefix {
+				t.Errorf("GenerateLuhnNumber(%q, %d) error = %v, want %v", tt.prefix, tt.length, err, ErrInvalidPrefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package pan — проверка, определение платёжной системы, форматирование и маскирование номеров карт (PAN)
+package pan
+
+import (
+	"BankSystem/internal/utils"
+	"errors"
+	"strings"
+)
+
+const (
+	MinLength = 13
+	MaxLength = 19
+)
+
+// Платёжные системы; значения совпадают с card_products.payment_system
+const (
+	SchemeMir        = "mir"
+	SchemeVisa       = "visa"
+	SchemeMastercard = "mastercard"
+)
+
+var (
+	ErrInvalidCharacters = errors.New("card number must contain only digits")
+	ErrInvalidLength     = errors.New("card number must be 13 to 19 digits long")
+	ErrInvalidChecksum   = errors.New("card number checksum is invalid")
+)
+
+// Normalize убирает пробелы и дефисы, которыми номер разделяют при вводе
+func Normalize(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+// Validate проверяет, что номер состоит из цифр, имеет допустимую длину и верную контрольную цифру
+func Validate(number string) error {
+	for i := 0; i < len(number); i++ {
+		if number[i] < '0' || number[i] > '9' {
+			return ErrInvalidCharacters
+		}
+	}
+	if len(number) < MinLength || len(number) > MaxLength {
+		return ErrInvalidLength
+	}
+	if !utils.IsLuhnValid(number) {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// Scheme определяет платёжную систему по IIN (первым цифрам номера). Пустая строка — система не распознана.
+func Scheme(number string) string {
+	switch {
+	case inRange(number, 4, 2200, 2204):
+		return SchemeMir
+	case strings.HasPrefix(number, "4"):
+		return SchemeVisa
+	case inRange(number, 2, 51, 55), inRange(number, 4, 2221, 2720):
+		return SchemeMastercard
+	}
+	return ""
+}
+
+// Format разбивает номер на группы по 4 цифры
+func Format(number string) string {
+	var b strings.Builder
+	for i := 0; i < len(number); i++ {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteByte(number[i])
+	}
+	return b.String()
+}
+
+// Mask скрывает все цифры, кроме последних четырёх: "**** **** **** 1234".
+// Используется везде, где номер показывается не владельцу в момент выпуска: в ответах, журналах и письмах.
+func Mask(number string) string {
+	return "**** **** **** " + Last4(number)
+}
+
+// Last4 — последние четыре цифры номера
+func Last4(number string) string {
+	if len(number) < 4 {
+		return ""
+	}
+	return number[len(number)-4:]
+}
+
+// inRange — попадают ли первые digits цифр номера в диапазон [from, to]
+func inRange(number string, digits int, from int, to int) bool {
+	if len(number) < digits {
+		return false
+	}
+	prefix := 0
+	for i := 0; i < digits; i++ {
+		if number[i] < '0' || number[i] > '9' {
+			return false
+		}
+		prefix = prefix*10 + int(number[i]-'0')
+	}
+	return prefix >= from && prefix <= to
+}
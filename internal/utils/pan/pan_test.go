@@ -0,0 +1,111 @@
+package pan
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+		want   error
+	}{
+		{"visa", "4111111111111111", nil},
+		{"mir", "2200000000000004", nil},
+		{"19 digits", "2204123456789012343", nil},
+		{"13 digits", "4222222222222", nil},
+		{"spaces", "4111 1111 1111 1111", ErrInvalidCharacters},
+		{"letters", "411111111111111a", ErrInvalidCharacters},
+		{"too short", "411111111111", ErrInvalidLength},
+		{"too long", "41111111111111111111", ErrInvalidLength},
+		{"bad checksum", "4111111111111112", ErrInvalidChecksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Validate(tt.number); got != tt.want {
+				t.Errorf("Validate(%q) = %v, want %v", tt.number, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheme(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"2200000000000004", SchemeMir},
+		{"2204123456789012343", SchemeMir},
+		{"2205000000000000", ""},
+		{"4111111111111111", SchemeVisa},
+		{"5555555555554444", SchemeMastercard},
+		{"5100000000000008", SchemeMastercard},
+		{"5600000000000000", ""},
+		{"2221000000000009", SchemeMastercard},
+		{"2720990000000000", SchemeMastercard},
+		{"2721000000000000", ""},
+		{"378282246310005", ""},
+		{"22", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if got := Scheme(tt.number); got != tt.want {
+				t.Errorf("Scheme(%q) = %q, want %q", tt.number, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"4111111111111111", "**** **** **** 1111"},
+		{"2204123456789012343", "**** **** **** 2343"},
+		{"123", "**** **** **** "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if got := Mask(tt.number); got != tt.want {
+				t.Errorf("Mask(%q) = %q, want %q", tt.number, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"4111111111111111", "4111 1111 1111 1111"},
+		{"2204123456789012343", "2204 1234 5678 9012 343"},
+		{"4111", "4111"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if got := Format(tt.number); got != tt.want {
+				t.Errorf("Format(%q) = %q, want %q", tt.number, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"4111 1111 1111 1111", "4111111111111111"},
+		{"4111-1111-1111-1111", "4111111111111111"},
+		{"4111111111111111", "4111111111111111"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if got := Normalize(tt.number); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.number, got, tt.want)
+			}
+		})
+	}
+}
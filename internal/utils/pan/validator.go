@@ -0,0 +1,17 @@
+package pan
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterValidation регистрирует в gin тег `binding:"pan"` для проверки номера карты
+func RegisterValidation() error {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil
+	}
+	return engine.RegisterValidation("pan", func(fl validator.FieldLevel) bool {
+		return Validate(fl.Field().String()) == nil
+	})
+}
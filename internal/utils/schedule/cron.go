@@ -0,0 +1,162 @@
+package schedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronSearchDays — сколько дней вперёд ищется запуск; выражения вроде "0 0 30 2 *" никогда не срабатывают
+const maxCronSearchDays = 5 * 366
+
+// cron — cron-выражение из пяти полей. Поддерживаются *, числа, диапазоны a-b, списки через запятую и шаги /n.
+// День недели: 0–7, где 0 и 7 — воскресенье. Если заданы и день месяца, и день недели, достаточно совпадения любого из них.
+type cron struct {
+	minutes  [60]bool
+	hours    [24]bool
+	days     [32]bool
+	months   [13]bool
+	weekdays [7]bool
+	// anyDay и anyWeekday — поле задано как *
+	anyDay     bool
+	anyWeekday bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var (
+	minuteField  = cronField{0, 59}
+	hourField    = cronField{0, 23}
+	dayField     = cronField{1, 31}
+	monthField   = cronField{1, 12}
+	weekdayField = cronField{0, 7}
+)
+
+// ParseCron разбирает cron-выражение из пяти полей
+func ParseCron(expr string) (Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, ErrInvalidSchedule
+	}
+
+	c := &cron{anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+	if err := minuteField.parse(fields[0], c.minutes[:]); err != nil {
+		return nil, err
+	}
+	if err := hourField.parse(fields[1], c.hours[:]); err != nil {
+		return nil, err
+	}
+	if err := dayField.parse(fields[2], c.days[:]); err != nil {
+		return nil, err
+	}
+	if err := monthField.parse(fields[3], c.months[:]); err != nil {
+		return nil, err
+	}
+	weekdays := make([]bool, 8)
+	if err := weekdayField.parse(fields[4], weekdays); err != nil {
+		return nil, err
+	}
+	copy(c.weekdays[:], weekdays)
+	c.weekdays[0] = c.weekdays[0] || weekdays[7]
+	return c, nil
+}
+
+// parse отмечает в set значения поля. Поле — список через запятую из *, n, a-b с необязательным шагом /n.
+func (f cronField) parse(value string, set []bool) error {
+	for _, part := range strings.Split(value, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return ErrInvalidSchedule
+			}
+		}
+
+		from, to := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if from, err = f.number(bounds[0]); err != nil {
+				return err
+			}
+			if to, err = f.number(bounds[1]); err != nil {
+				return err
+			}
+			if from > to {
+				return ErrInvalidSchedule
+			}
+		default:
+			n, err := f.number(rangePart)
+			if err != nil {
+				return err
+			}
+			from = n
+			// "5/15" — с 5 до конца диапазона с шагом 15
+			if step == 1 {
+				to = n
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			set[v] = true
+		}
+	}
+	return nil
+}
+
+func (f cronField) number(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < f.min || n > f.max {
+		return 0, ErrInvalidSchedule
+	}
+	return n, nil
+}
+
+func (c *cron) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < maxCronSearchDays; i++ {
+		if c.matchesDay(day) {
+			for hour := 0; hour < 24; hour++ {
+				if !c.hours[hour] {
+					continue
+				}
+				for minute := 0; minute < 60; minute++ {
+					if !c.minutes[minute] {
+						continue
+					}
+					candidate := day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+					if !candidate.Before(t) {
+						return candidate
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+func (c *cron) matchesDay(day time.Time) bool {
+	if !c.months[day.Month()] {
+		return false
+	}
+	dayMatch := c.days[day.Day()]
+	weekdayMatch := c.weekdays[day.Weekday()]
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekdayMatch
+	case c.anyWeekday:
+		return dayMatch
+	}
+	return dayMatch || weekdayMatch
+}
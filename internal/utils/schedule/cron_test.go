@@ -0,0 +1,74 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1-a * * * *",
+		"1,,2 * * * *",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseCron(expr); err != ErrInvalidSchedule {
+				t.Errorf("ParseCron(%q) error = %v, want %v", expr, err, ErrInvalidSchedule)
+			}
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// 2024-03-08 — пятница, 2024-03-10 — воскресенье
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"every minute", "* * * * *", date(2024, 3, 8, 10, 7), date(2024, 3, 8, 10, 8)},
+		{"strictly after", "0 9 * * *", date(2024, 3, 8, 9, 0), date(2024, 3, 9, 9, 0)},
+		{"seconds are ignored", "0 9 * * *", date(2024, 3, 8, 8, 59).Add(30 * time.Second), date(2024, 3, 8, 9, 0)},
+		{"step", "*/15 * * * *", date(2024, 3, 8, 10, 7), date(2024, 3, 8, 10, 15)},
+		{"step from value", "5/20 * * * *", date(2024, 3, 8, 10, 6), date(2024, 3, 8, 10, 25)},
+		{"step over range", "0 8-18/5 * * *", date(2024, 3, 8, 13, 0), date(2024, 3, 8, 18, 0)},
+		{"list", "0 8,20 * * *", date(2024, 3, 8, 8, 0), date(2024, 3, 8, 20, 0)},
+		{"weekdays skip weekend", "0 9 * * 1-5", date(2024, 3, 8, 10, 0), date(2024, 3, 11, 9, 0)},
+		{"sunday as 0", "30 6 * * 0", date(2024, 3, 8, 0, 0), date(2024, 3, 10, 6, 30)},
+		{"sunday as 7", "30 6 * * 7", date(2024, 3, 8, 0, 0), date(2024, 3, 10, 6, 30)},
+		{"first day of month", "0 0 1 * *", date(2024, 1, 15, 0, 0), date(2024, 2, 1, 0, 0)},
+		{"month and year rollover", "0 0 1 1 *", date(2024, 1, 1, 0, 0), date(2025, 1, 1, 0, 0)},
+		{"leap day", "0 12 29 2 *", date(2024, 3, 1, 0, 0), date(2028, 2, 29, 12, 0)},
+		{"31st skips short months", "0 0 31 * *", date(2024, 3, 31, 0, 0), date(2024, 5, 31, 0, 0)},
+		{"day of month or weekday: day first", "0 12 13 * 5", date(2024, 3, 8, 12, 0), date(2024, 3, 13, 12, 0)},
+		{"day of month or weekday: weekday first", "0 12 13 * 5", date(2024, 3, 13, 12, 0), date(2024, 3, 15, 12, 0)},
+		{"weekday restricted by month", "0 0 * 4 1", date(2024, 3, 8, 0, 0), date(2024, 4, 1, 0, 0)},
+		{"never matches", "0 0 30 2 *", date(2024, 1, 1, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error: %v", tt.expr, err)
+			}
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
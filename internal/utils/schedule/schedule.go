@@ -0,0 +1,83 @@
+// Package schedule — расписания регулярных операций: daily, weekly, monthly и cron-выражения.
+// Все расчёты ведутся в UTC с точностью до минуты.
+package schedule
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+var ErrInvalidSchedule = errors.New("recurrence must be daily, weekly, monthly or a cron expression")
+
+// Schedule возвращает ближайший запуск строго после after
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// Parse разбирает расписание. Для daily, weekly и monthly запуски отсчитываются от start:
+// monthly выполняется в тот же день месяца, а если такого дня нет — в последний день месяца.
+// Иначе recurrence разбирается как cron-выражение из пяти полей: минута, час, день месяца, месяц, день недели.
+func Parse(recurrence string, start time.Time) (Schedule, error) {
+	start = start.UTC().Truncate(time.Minute)
+	switch strings.ToLower(strings.TrimSpace(recurrence)) {
+	case Daily:
+		return &interval{start: start, days: 1}, nil
+	case Weekly:
+		return &interval{start: start, days: 7}, nil
+	case Monthly:
+		return &interval{start: start, months: 1}, nil
+	}
+	return ParseCron(recurrence)
+}
+
+// First — первый запуск не раньше start
+func First(s Schedule, start time.Time) time.Time {
+	return s.Next(start.UTC().Truncate(time.Minute).Add(-time.Minute))
+}
+
+// interval — запуски через равное число дней или месяцев от start
+type interval struct {
+	start  time.Time
+	days   int
+	months int
+}
+
+func (i *interval) Next(after time.Time) time.Time {
+	after = after.UTC()
+	if after.Before(i.start) {
+		return i.start
+	}
+
+	if i.days > 0 {
+		step := time.Duration(i.days) * 24 * time.Hour
+		n := int(after.Sub(i.start)/step) + 1
+		return i.start.Add(time.Duration(n) * step)
+	}
+
+	n := (after.Year()-i.start.Year())*12 + int(after.Month()) - int(i.start.Month())
+	for {
+		next := addMonths(i.start, n)
+		if next.After(after) {
+			return next
+		}
+		n += i.months
+	}
+}
+
+// addMonths сдвигает t на n месяцев, не перескакивая в следующий месяц для 29–31 числа
+func addMonths(t time.Time, n int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,92 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	start := date(2024, time.March, 10, 8, 30)
+	tests := []struct {
+		recurrence string
+		wantErr    bool
+	}{
+		{"daily", false},
+		{" Weekly ", false},
+		{"MONTHLY", false},
+		{"0 9 * * 1-5", false},
+		{"hourly", true},
+		{"", true},
+		{"* * * *", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.recurrence, func(t *testing.T) {
+			_, err := Parse(tt.recurrence, start)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", tt.recurrence, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIntervalNext(t *testing.T) {
+	tests := []struct {
+		name       string
+		recurrence string
+		start      time.Time
+		after      time.Time
+		want       time.Time
+	}{
+		{"daily before start", Daily, date(2024, 3, 10, 8, 30), date(2024, 3, 1, 0, 0), date(2024, 3, 10, 8, 30)},
+		{"daily at start", Daily, date(2024, 3, 10, 8, 30), date(2024, 3, 10, 8, 30), date(2024, 3, 11, 8, 30)},
+		{"daily between runs", Daily, date(2024, 3, 10, 8, 30), date(2024, 3, 12, 9, 0), date(2024, 3, 13, 8, 30)},
+		{"weekly", Weekly, date(2024, 3, 10, 8, 30), date(2024, 3, 10, 8, 31), date(2024, 3, 17, 8, 30)},
+		{"weekly after downtime", Weekly, date(2024, 3, 10, 8, 30), date(2024, 4, 1, 0, 0), date(2024, 4, 7, 8, 30)},
+		{"monthly", Monthly, date(2024, 1, 15, 12, 0), date(2024, 1, 15, 12, 0), date(2024, 2, 15, 12, 0)},
+		{"monthly 31st in leap february", Monthly, date(2024, 1, 31, 10, 0), date(2024, 1, 31, 10, 0), date(2024, 2, 29, 10, 0)},
+		{"monthly 31st returns to 31st", Monthly, date(2024, 1, 31, 10, 0), date(2024, 2, 29, 10, 0), date(2024, 3, 31, 10, 0)},
+		{"monthly 31st in april", Monthly, date(2024, 1, 31, 10, 0), date(2024, 3, 31, 10, 0), date(2024, 4, 30, 10, 0)},
+		{"monthly 29th in common february", Monthly, date(2023, 1, 29, 0, 0), date(2023, 1, 29, 0, 0), date(2023, 2, 28, 0, 0)},
+		{"monthly across year", Monthly, date(2024, 11, 30, 6, 0), date(2024, 12, 30, 6, 0), date(2025, 1, 30, 6, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.recurrence, tt.start)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.recurrence, err)
+			}
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirst(t *testing.T) {
+	tests := []struct {
+		name       string
+		recurrence string
+		start      time.Time
+		want       time.Time
+	}{
+		{"daily starts at start", Daily, date(2024, 3, 10, 8, 30), date(2024, 3, 10, 8, 30)},
+		{"seconds are truncated", Daily, date(2024, 3, 10, 8, 30).Add(45 * time.Second), date(2024, 3, 10, 8, 30)},
+		{"cron matching start", "30 8 * * *", date(2024, 3, 10, 8, 30), date(2024, 3, 10, 8, 30)},
+		{"cron after start", "0 9 * * *", date(2024, 3, 10, 9, 1), date(2024, 3, 11, 9, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.recurrence, tt.start)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.recurrence, err)
+			}
+			if got := First(s, tt.start); !got.Equal(tt.want) {
+				t.Errorf("First() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,13 @@ import (
 	"BankSystem/internal/config"
 	"BankSystem/internal/db"
 	"BankSystem/internal/handlers"
+	"BankSystem/internal/jobs"
 	"BankSystem/internal/middleware"
+	"BankSystem/internal/models"
+	"BankSystem/internal/ratelimit"
 	repositories "BankSystem/internal/repositories"
+	"BankSystem/internal/security"
+	"BankSystem/internal/utils/pan"
 	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -18,6 +23,7 @@ import (
 	"gorm.io/gorm"
 	"log"
 	"os"
+	"strconv"
 
 	"BankSystem/internal/services"
 	account_service "BankSystem/internal/services/account"
@@ -31,14 +37,33 @@ import (
 // @version 1.0
 // @description API Server for Bank System
 // @host localhost:8080
-// @BasePath /api
+// @BasePath /
 // @schemes http https
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 	logger := logrus.New()
 
 	dbCfg := config.LoadDB()
 	dsn := db.BuildDSN(dbCfg)
-	crypto := config.LoadCrypto()
+	crypto, err := config.LoadCrypto()
+	if err != nil {
+		logger.Fatalf("Ошибка загрузки настроек шифрования: %v", err)
+	}
+	authCfg := config.LoadAuth()
+	jwtCfg, err := config.LoadJWT()
+	if err != nil {
+		logger.Fatalf("Ошибка загрузки настроек JWT: %v", err)
+	}
+	rateLimitCfg := config.LoadRateLimit()
+	jobsCfg := config.LoadJobs()
+	cardCfg := config.LoadCard()
+	standingOrderCfg := config.LoadStandingOrder()
+	httpCfg := config.LoadHTTP()
+	if cardCfg.ATMFeeAccountID == 0 {
+		logger.Warn("CARD_ATM_FEE_ACCOUNT_ID не задан, комиссия за снятие наличных не взимается")
+	}
 	runMigrations(dsn)
 	ctx := context.Background()
 
@@ -56,47 +81,225 @@ func main() {
 	userRepository := repositories.NewUserRepository(dbConnect)
 	accountRepository := repositories.NewAccountRepository(dbConnect)
 	cardRepository := repositories.NewCardRepository(dbConnect)
+	userTokenRepository := repositories.NewUserTokenRepository(dbConnect)
+	loginThrottleRepository := repositories.NewLoginThrottleRepository(dbConnect)
+	auditRepository := repositories.NewAuditRepository(dbConnect)
+	transactionRepository := repositories.NewTransactionRepository(dbConnect)
+	paymentHoldRepository := repositories.NewPaymentHoldRepository(dbConnect)
+	merchantRepository := repositories.NewMerchantRepository(dbConnect)
+	cardProductRepository := repositories.NewCardProductRepository(dbConnect)
+	cardTokenRepository := repositories.NewCardTokenRepository(dbConnect)
+	standingOrderRepository := repositories.NewStandingOrderRepository(dbConnect)
+	beneficiaryRepository := repositories.NewBeneficiaryRepository(dbConnect)
+
+	cardKeyring, err := security.NewKeyring(crypto.CardKeys, crypto.CardActiveKeyID)
+	if err != nil {
+		logger.Fatalf("Ошибка загрузки ключей шифрования карт: %v", err)
+	}
 
 	accountService := account_service.NewAccountService(accountRepository, logger)
 	userService := services.NewUserService(userRepository, accountService, logger)
 	authService := services.NewAuthService(userRepository, logger)
 	mailService := services.NewMailService(os.Getenv("MAILGUN_API_KEY"), os.Getenv("MAILGUN_DOMAIN"), logger)
-	cardService := services.NewCardService(dbConnect, cardRepository, accountRepository, userRepository, accountService, mailService, crypto.HMACKey, logger)
+	cardService := services.NewCardService(dbConnect, cardRepository, accountRepository, userRepository, transactionRepository, paymentHoldRepository, merchantRepository, cardProductRepository, cardTokenRepository, accountService, mailService, cardKeyring, crypto.HMACKey, crypto.CardIndexKey, cardCfg, logger)
+	passwordService := services.NewPasswordService(userRepository, userTokenRepository, mailService, authCfg, logger)
+	verificationService := services.NewVerificationService(userRepository, mailService, authCfg, jwtCfg.LinkSecret, logger)
+	auditService := services.NewAuditService(auditRepository, logger)
+	loginProtectionService := services.NewLoginProtectionService(loginThrottleRepository, userTokenRepository, auditService, mailService, authCfg, logger)
+	merchantService := services.NewMerchantService(merchantRepository, accountRepository, paymentHoldRepository, logger)
+	standingOrderService := services.NewStandingOrderService(standingOrderRepository, accountRepository, userRepository, accountService, mailService, standingOrderCfg, logger)
+	beneficiaryService := services.NewBeneficiaryService(beneficiaryRepository, accountRepository, cardRepository, cardService, accountService, logger)
+	adminService := services.NewAdminService(userRepository, accountRepository, transactionRepository, accountService, auditService, logger)
+
+	if _, err := cardService.BackfillNumberIndex(); err != nil {
+		logger.Fatalf("Ошибка заполнения индекса номеров карт: %v", err)
+	}
+
+	// go run main.go grant-admin <email> — назначить зарегистрированному пользователю роль администратора и завершиться
+	if len(os.Args) > 2 && os.Args[1] == "grant-admin" {
+		user, previous, err := userService.GrantAdmin(os.Args[2])
+		if err != nil {
+			logger.Fatalf("Ошибка назначения администратора: %v", err)
+		}
+		auditService.TryRecord(services.AuditEntry{
+			Action:     models.AuditActionAdminGrant,
+			EntityType: models.AuditEntityUser,
+			EntityID:   strconv.Itoa(int(user.ID)),
+			Before:     map[string]string{"role": previous},
+			After:      map[string]string{"role": user.Role},
+			Details:    map[string]interface{}{"source": "cli"},
+		})
+		return
+	}
+
+	// go run main.go reindex-cards — пересчитать blind index всех карт после смены CARD_INDEX_KEY и завершиться
+	if len(os.Args) > 1 && os.Args[1] == "reindex-cards" {
+		if _, err := cardService.ReindexCards(); err != nil {
+			logger.Fatalf("Ошибка пересчёта индекса карт: %v", err)
+		}
+		return
+	}
+
+	// go run main.go reencrypt-cards — перешифровать все карты активным ключом и завершиться
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt-cards" {
+		if _, err := cardService.ReencryptCards(); err != nil {
+			logger.Fatalf("Ошибка перешифрования карт: %v", err)
+		}
+		return
+	}
+
+	scheduler := jobs.NewScheduler(logger)
+	scheduler.Every("card-expiry", jobsCfg.CardExpiryInterval, func(ctx context.Context) error {
+		_, err := cardService.ExpireCards()
+		return err
+	})
+	scheduler.Every("payment-hold-expiry", jobsCfg.HoldExpiryInterval, func(ctx context.Context) error {
+		_, err := cardService.ExpireHolds()
+		return err
+	})
+	scheduler.Every("standing-orders", jobsCfg.StandingOrderInterval, func(ctx context.Context) error {
+		_, err := standingOrderService.ExecuteDue()
+		return err
+	})
+	scheduler.Start(ctx)
+
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if rateLimitCfg.Store == config.RateLimitStorePostgres {
+		rateLimitStore = ratelimit.NewPostgresStore(dbConnect)
+	}
+	paymentsLimit := middleware.RateLimitMiddleware(rateLimitStore, "payments", rateLimitCfg.Payments)
 
-	authHandler := handlers.NewAuthHandler(userService)
+	authHandler := handlers.NewAuthHandler(userService, passwordService, verificationService, loginProtectionService, auditService)
+	if err := pan.RegisterValidation(); err != nil {
+		logger.Fatalf("Ошибка регистрации валидатора номера карты: %v", err)
+	}
 	r := gin.Default()
-	auth := r.Group("/auth")
+	// IP клиента используется для блокировки входа и лимитов, поэтому X-Forwarded-For принимается только от известных прокси
+	if err := r.SetTrustedProxies(httpCfg.TrustedProxies); err != nil {
+		logger.Fatalf("Ошибка настройки доверенных прокси: %v", err)
+	}
+	r.Use(middleware.RequestIDMiddleware())
+	auth := r.Group("/auth", middleware.RateLimitMiddleware(rateLimitStore, "auth", rateLimitCfg.Auth))
 	{
 		auth.POST("/register", authHandler.Register)
-		auth.POST("/login", authHandler.Login)
+		auth.POST("/login", middleware.RateLimitMiddleware(rateLimitStore, "login", rateLimitCfg.Login), authHandler.Login)
+		auth.POST("/password/forgot", authHandler.ForgotPassword)
+		auth.POST("/password/reset", authHandler.ResetPassword)
+		auth.GET("/verify", authHandler.VerifyEmail)
+		auth.POST("/verify/resend", authHandler.ResendVerification)
+		auth.GET("/unlock", authHandler.UnlockLogin)
 	}
 
+	authMiddleware := middleware.AuthMiddleware(userRepository)
+
 	userHandler := handlers.NewUserHandler(userRepository, authService)
 	user := r.Group("/user")
 	{
-		user.GET("/profile", middleware.AuthMiddleware(), userHandler.GetCurrentUser)
+		user.GET("/profile", authMiddleware, userHandler.GetCurrentUser)
 	}
 
-	accountHandler := handlers.NewAccountHandler(accountService, userService, authService)
+	// Операции с деньгами доступны только после подтверждения email
+	verifiedEmail := middleware.VerifiedEmailMiddleware(userRepository)
+
+	accountHandler := handlers.NewAccountHandler(accountService, userService, authService, auditService)
 	account := r.Group("/account")
 	{
-		account.POST("/create", middleware.AuthMiddleware(), accountHandler.CreateAccount)
-		account.GET("/all", middleware.AuthMiddleware(), accountHandler.GetAllAccounts)
-		account.POST("/deposit", middleware.AuthMiddleware(), accountHandler.Deposit)
-		account.POST("/withdraw", middleware.AuthMiddleware(), accountHandler.Withdraw)
+		account.POST("/create", authMiddleware, accountHandler.CreateAccount)
+		account.GET("/all", authMiddleware, accountHandler.GetAllAccounts)
+		account.POST("/deposit", authMiddleware, verifiedEmail, accountHandler.Deposit)
+		account.POST("/withdraw", authMiddleware, verifiedEmail, accountHandler.Withdraw)
+		account.PUT("/default", authMiddleware, accountHandler.SetDefaultAccount)
 	}
 
+	cardHandler := handlers.NewCardHandler(userService, accountService, cardService, authService, accountRepository, auditService)
 	transfer := r.Group("/transfer")
 	{
-		transfer.POST("/create", middleware.AuthMiddleware(), accountHandler.Transfer)
+		transfer.POST("/create", authMiddleware, paymentsLimit, verifiedEmail, accountHandler.Transfer)
+		transfer.POST("/card", authMiddleware, paymentsLimit, verifiedEmail, cardHandler.TransferByCard)
+		transfer.GET("/preview", authMiddleware, paymentsLimit, accountHandler.PreviewTransfer)
 	}
 
-	cardHandler := handlers.NewCardHandler(userService, accountService, cardService, authService, accountRepository)
 	card := r.Group("/card")
 	{
-		card.POST("/create", middleware.AuthMiddleware(), cardHandler.CreateCard)
-		card.GET("/all", middleware.AuthMiddleware(), cardHandler.GetCards)
-		card.POST("/payment", middleware.AuthMiddleware(), cardHandler.PayWithCard)
+		card.POST("/create", authMiddleware, cardHandler.CreateCard)
+		card.GET("/all", authMiddleware, cardHandler.GetCards)
+		card.GET("/products", authMiddleware, cardHandler.GetCardProducts)
+		card.POST("/payment", authMiddleware, paymentsLimit, verifiedEmail, cardHandler.PayWithCard)
+		card.POST("/:id/block", authMiddleware, cardHandler.BlockCard)
+		card.POST("/:id/unblock", authMiddleware, cardHandler.UnblockCard)
+		card.POST("/:id/lost", authMiddleware, cardHandler.ReportLost)
+		card.POST("/:id/reissue", authMiddleware, verifiedEmail, cardHandler.ReissueCard)
+		card.GET("/:id/limits", authMiddleware, cardHandler.GetCardLimits)
+		card.PUT("/:id/limits", authMiddleware, cardHandler.SetCardLimits)
+		card.POST("/:id/pin", authMiddleware, cardHandler.SetCardPIN)
+		card.PUT("/:id/pin", authMiddleware, cardHandler.ChangeCardPIN)
+		card.GET("/:id/transactions", authMiddleware, cardHandler.GetCardTransactions)
+		card.GET("/:id/tokens", authMiddleware, cardHandler.GetCardTokens)
+		card.POST("/:id/tokens/:token_id/revoke", authMiddleware, cardHandler.RevokeCardToken)
+	}
+
+	atmHandler := handlers.NewATMHandler(cardService, auditService)
+	atm := r.Group("/atm", authMiddleware)
+	{
+		atm.POST("/withdraw", paymentsLimit, verifiedEmail, atmHandler.Withdraw)
+	}
+
+	paymentHandler := handlers.NewPaymentHandler(cardService, authService, auditService)
+	payment := r.Group("/payment", authMiddleware)
+	{
+		payment.POST("/authorize", paymentsLimit, verifiedEmail, paymentHandler.Authorize)
+		payment.POST("/token", paymentsLimit, verifiedEmail, paymentHandler.PayWithToken)
+		payment.POST("/:id/confirm", paymentsLimit, paymentHandler.Confirm)
+		payment.POST("/:id/capture", paymentsLimit, verifiedEmail, paymentHandler.Capture)
+		payment.POST("/:id/void", paymentsLimit, verifiedEmail, paymentHandler.Void)
+		payment.POST("/:id/refund", paymentsLimit, verifiedEmail, paymentHandler.Refund)
+	}
+
+	merchantHandler := handlers.NewMerchantHandler(merchantService, authService, auditService)
+	merchant := r.Group("/merchant", authMiddleware)
+	{
+		merchant.POST("/create", verifiedEmail, merchantHandler.CreateMerchant)
+		merchant.GET("/all", merchantHandler.GetMerchants)
+		merchant.GET("/:id/payments", merchantHandler.GetMerchantPayments)
+	}
+
+	standingOrderHandler := handlers.NewStandingOrderHandler(standingOrderService, authService, auditService)
+	standingOrder := r.Group("/standing-order", authMiddleware)
+	{
+		standingOrder.POST("/create", verifiedEmail, standingOrderHandler.CreateStandingOrder)
+		standingOrder.GET("/all", standingOrderHandler.GetStandingOrders)
+		standingOrder.GET("/:id/executions", standingOrderHandler.GetExecutions)
+		standingOrder.POST("/:id/cancel", standingOrderHandler.CancelStandingOrder)
+	}
+
+	beneficiaryHandler := handlers.NewBeneficiaryHandler(beneficiaryService, authService, auditService)
+	beneficiary := r.Group("/beneficiary", authMiddleware)
+	{
+		beneficiary.POST("/create", paymentsLimit, verifiedEmail, beneficiaryHandler.CreateBeneficiary)
+		beneficiary.GET("/all", beneficiaryHandler.GetBeneficiaries)
+		beneficiary.POST("/:id/delete", beneficiaryHandler.DeleteBeneficiary)
+	}
+
+	template := r.Group("/template", authMiddleware)
+	{
+		template.POST("/create", paymentsLimit, verifiedEmail, beneficiaryHandler.CreateTemplate)
+		template.GET("/all", beneficiaryHandler.GetTemplates)
+		template.POST("/:id/delete", beneficiaryHandler.DeleteTemplate)
+		template.POST("/:id/execute", paymentsLimit, verifiedEmail, beneficiaryHandler.ExecuteTemplate)
+	}
+
+	adminHandler := handlers.NewAdminHandler(adminService)
+	admin := r.Group("/admin", authMiddleware)
+	{
+		admin.GET("/users", middleware.RequirePermission(security.PermissionUsersRead), adminHandler.ListUsers)
+		admin.GET("/accounts/:id", middleware.RequirePermission(security.PermissionAccountsRead), adminHandler.GetAccount)
+		admin.GET("/accounts/:id/transactions", middleware.RequirePermission(security.PermissionAccountsRead), adminHandler.GetAccountTransactions)
+		admin.POST("/accounts/:id/freeze", middleware.RequirePermission(security.PermissionAccountsManage), adminHandler.FreezeAccount)
+		admin.POST("/accounts/:id/block", middleware.RequirePermission(security.PermissionAccountsManage), adminHandler.BlockAccount)
+		admin.POST("/accounts/:id/activate", middleware.RequirePermission(security.PermissionAccountsManage), adminHandler.ActivateAccount)
+		admin.POST("/accounts/:id/close", middleware.RequirePermission(security.PermissionAccountsManage), adminHandler.CloseAccount)
+		admin.GET("/audit", middleware.RequirePermission(security.PermissionAuditRead), adminHandler.ListAuditLogs)
+		admin.GET("/audit/verify", middleware.RequirePermission(security.PermissionAuditRead), adminHandler.VerifyAuditChain)
 	}
 
 	// Swagger
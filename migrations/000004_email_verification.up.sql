@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_sent_at TIMESTAMP;
+
+-- Пользователи, зарегистрированные до появления подтверждения email, считаются подтверждёнными
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
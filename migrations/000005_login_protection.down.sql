@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS audit_logs CASCADE;
+DROP TABLE IF EXISTS login_throttles CASCADE;
@@ -0,0 +1,2 @@
+ALTER TABLE accounts DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE accounts DROP COLUMN IF EXISTS status;
@@ -0,0 +1,3 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'active'
+    CHECK(status IN ('active', 'frozen', 'blocked', 'closed'));
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP;
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs;
+DROP TRIGGER IF EXISTS audit_logs_no_update_delete ON audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_append_only();
+
+DROP INDEX IF EXISTS idx_audit_logs_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_actor_id;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS request_id;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS after_value;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS before_value;
@@ -0,0 +1,23 @@
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS before_value JSONB;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS after_value JSONB;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS user_agent TEXT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS request_id VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash CHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash CHAR(64);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены на уровне БД
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+CREATE TRIGGER audit_logs_no_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_cards_status_expired_at;
+ALTER TABLE cards DROP COLUMN IF EXISTS reissued_from_id;
+ALTER TABLE cards DROP COLUMN IF EXISTS status_changed_at;
+ALTER TABLE cards DROP COLUMN IF EXISTS status;
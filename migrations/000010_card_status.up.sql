@@ -0,0 +1,7 @@
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'active'
+    CHECK(status IN ('active', 'blocked', 'lost', 'expired', 'closed'));
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP;
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS reissued_from_id INTEGER REFERENCES cards(id);
+CREATE INDEX IF NOT EXISTS idx_cards_status_expired_at ON cards(status, expired_at);
+
+UPDATE cards SET status = 'expired', status_changed_at = CURRENT_TIMESTAMP WHERE expired_at < CURRENT_TIMESTAMP;
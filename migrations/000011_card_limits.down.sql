@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_transactions_card_id_created_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS card_id;
+
+ALTER TABLE cards DROP COLUMN IF EXISTS hourly_payments_limit;
+ALTER TABLE cards DROP COLUMN IF EXISTS monthly_limit;
+ALTER TABLE cards DROP COLUMN IF EXISTS daily_limit;
+ALTER TABLE cards DROP COLUMN IF EXISTS per_transaction_limit;
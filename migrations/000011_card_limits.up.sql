@@ -0,0 +1,7 @@
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS per_transaction_limit NUMERIC(12,2) CHECK(per_transaction_limit > 0);
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS daily_limit NUMERIC(12,2) CHECK(daily_limit > 0);
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS monthly_limit NUMERIC(12,2) CHECK(monthly_limit > 0);
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS hourly_payments_limit INTEGER CHECK(hourly_payments_limit > 0);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS card_id INTEGER REFERENCES cards(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_transactions_card_id_created_at ON transactions(card_id, created_at);
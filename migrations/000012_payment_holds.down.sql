@@ -0,0 +1,8 @@
+DELETE FROM transactions WHERE transaction_type = 'refund';
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK(transaction_type IN ('transfer', 'deposit', 'withdrawal', 'payment'));
+ALTER TABLE transactions DROP COLUMN IF EXISTS hold_id;
+
+DROP TABLE IF EXISTS payment_holds;
+ALTER TABLE accounts DROP COLUMN IF EXISTS hold_amount;
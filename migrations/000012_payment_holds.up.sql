@@ -0,0 +1,26 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS hold_amount NUMERIC(12,2) NOT NULL DEFAULT 0.00 CHECK(hold_amount >= 0);
+
+CREATE TABLE IF NOT EXISTS payment_holds (
+    id SERIAL PRIMARY KEY,
+    card_id INTEGER NOT NULL REFERENCES cards(id),
+    account_id INTEGER NOT NULL REFERENCES accounts(id),
+    initiator_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    amount NUMERIC(12,2) NOT NULL CHECK(amount > 0),
+    captured_amount NUMERIC(12,2) NOT NULL DEFAULT 0.00 CHECK(captured_amount >= 0),
+    refunded_amount NUMERIC(12,2) NOT NULL DEFAULT 0.00 CHECK(refunded_amount >= 0 AND refunded_amount <= captured_amount),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    status VARCHAR(20) NOT NULL CHECK(status IN ('authorized', 'captured', 'voided', 'expired', 'refunded')),
+    expires_at TIMESTAMP NOT NULL,
+    captured_at TIMESTAMP,
+    released_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+    );
+CREATE INDEX IF NOT EXISTS idx_payment_holds_status_expires_at ON payment_holds(status, expires_at);
+CREATE INDEX IF NOT EXISTS idx_payment_holds_card_id_created_at ON payment_holds(card_id, created_at);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS hold_id INTEGER REFERENCES payment_holds(id) ON DELETE SET NULL;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK(transaction_type IN ('transfer', 'deposit', 'withdrawal', 'payment', 'refund'));
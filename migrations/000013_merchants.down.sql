@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payment_holds_merchant_id_created_at;
+ALTER TABLE payment_holds DROP COLUMN IF EXISTS merchant_id;
+DROP TABLE IF EXISTS merchants;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS merchants (
+    id SERIAL PRIMARY KEY,
+    owner_user_id INTEGER NOT NULL REFERENCES users(id),
+    name VARCHAR(255) NOT NULL,
+    settlement_account_id INTEGER NOT NULL REFERENCES accounts(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+    );
+CREATE INDEX IF NOT EXISTS idx_merchants_owner_user_id ON merchants(owner_user_id);
+
+ALTER TABLE payment_holds ADD COLUMN IF NOT EXISTS merchant_id INTEGER REFERENCES merchants(id);
+CREATE INDEX IF NOT EXISTS idx_payment_holds_merchant_id_created_at ON payment_holds(merchant_id, created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_cards_card_number_index;
+ALTER TABLE cards DROP COLUMN IF EXISTS card_number_index;
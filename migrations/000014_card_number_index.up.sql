@@ -0,0 +1,3 @@
+-- Заполняется при старте приложения, т.к. ключи шифрования и индекса в БД не хранятся
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS card_number_index VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cards_card_number_index ON cards(card_number_index);
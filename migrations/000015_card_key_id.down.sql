@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_cards_card_key_id;
+ALTER TABLE cards DROP COLUMN IF EXISTS card_key_id;
@@ -0,0 +1,3 @@
+-- NULL — номер зашифрован pgp_sym_encrypt до перехода на шифрование в приложении
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS card_key_id VARCHAR(32);
+CREATE INDEX IF NOT EXISTS idx_cards_card_key_id ON cards(card_key_id);
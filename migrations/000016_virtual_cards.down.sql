@@ -0,0 +1,3 @@
+ALTER TABLE cards DROP COLUMN IF EXISTS spending_cap;
+ALTER TABLE cards DROP COLUMN IF EXISTS single_use;
+ALTER TABLE cards DROP COLUMN IF EXISTS kind;
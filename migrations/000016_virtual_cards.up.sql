@@ -0,0 +1,4 @@
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS kind VARCHAR(10) NOT NULL DEFAULT 'physical'
+    CHECK(kind IN ('physical', 'virtual'));
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS single_use BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS spending_cap NUMERIC(12,2);
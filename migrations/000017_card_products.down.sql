@@ -0,0 +1,2 @@
+ALTER TABLE cards DROP COLUMN IF EXISTS product_id;
+DROP TABLE IF EXISTS card_products;
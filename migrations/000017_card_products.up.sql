@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS card_products (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    payment_system VARCHAR(16) NOT NULL CHECK(payment_system IN ('mir', 'visa', 'mastercard')),
+    bin VARCHAR(8) NOT NULL CHECK(bin ~ '^[0-9]{6,8}$'),
+    pan_length SMALLINT NOT NULL DEFAULT 16 CHECK(pan_length BETWEEN 13 AND 19),
+    validity_months SMALLINT NOT NULL DEFAULT 48 CHECK(validity_months > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+    );
+
+INSERT INTO card_products (code, name, payment_system, bin, pan_length, validity_months) VALUES
+    ('mir_debit', 'МИР Дебетовая', 'mir', '220070', 16, 48),
+    ('visa_classic', 'Visa Classic', 'visa', '427600', 16, 36),
+    ('mastercard_standard', 'Mastercard Standard', 'mastercard', '546900', 16, 36)
+ON CONFLICT (code) DO NOTHING;
+
+-- Карты, выпущенные до появления продуктов, остаются без продукта
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS product_id INTEGER REFERENCES card_products(id);
@@ -0,0 +1,2 @@
+ALTER TABLE payment_holds DROP COLUMN IF EXISTS token_id;
+DROP TABLE IF EXISTS card_tokens;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS card_tokens (
+    id SERIAL PRIMARY KEY,
+    card_id INTEGER NOT NULL REFERENCES cards(id),
+    merchant_id INTEGER NOT NULL REFERENCES merchants(id),
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    last4 CHAR(4) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'active' CHECK(status IN ('active', 'revoked')),
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+    );
+CREATE INDEX IF NOT EXISTS idx_card_tokens_card_id ON card_tokens(card_id);
+
+-- Оплата по токену мерчанта, без CVV
+ALTER TABLE payment_holds ADD COLUMN IF NOT EXISTS token_id INTEGER REFERENCES card_tokens(id);
@@ -0,0 +1,7 @@
+DELETE FROM transactions WHERE transaction_type = 'fee';
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK(transaction_type IN ('transfer', 'deposit', 'withdrawal', 'payment', 'refund'));
+
+ALTER TABLE cards DROP COLUMN IF EXISTS pin_attempts;
+ALTER TABLE cards DROP COLUMN IF EXISTS pin_hash;
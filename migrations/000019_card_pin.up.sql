@@ -0,0 +1,6 @@
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS pin_hash VARCHAR(255);
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS pin_attempts SMALLINT NOT NULL DEFAULT 0;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK(transaction_type IN ('transfer', 'deposit', 'withdrawal', 'payment', 'refund', 'fee'));
@@ -0,0 +1,7 @@
+UPDATE payment_holds SET status = 'voided' WHERE status = 'challenged';
+ALTER TABLE payment_holds DROP COLUMN IF EXISTS capture_on_confirm;
+ALTER TABLE payment_holds DROP COLUMN IF EXISTS otp_attempts;
+ALTER TABLE payment_holds DROP COLUMN IF EXISTS otp_hash;
+ALTER TABLE payment_holds DROP CONSTRAINT IF EXISTS payment_holds_status_check;
+ALTER TABLE payment_holds ADD CONSTRAINT payment_holds_status_check
+    CHECK(status IN ('authorized', 'captured', 'voided', 'expired', 'refunded'));
@@ -0,0 +1,7 @@
+-- challenged — сумма зарезервирована, оплата ждёт одноразовый код, отправленный владельцу карты
+ALTER TABLE payment_holds DROP CONSTRAINT IF EXISTS payment_holds_status_check;
+ALTER TABLE payment_holds ADD CONSTRAINT payment_holds_status_check
+    CHECK(status IN ('challenged', 'authorized', 'captured', 'voided', 'expired', 'refunded'));
+ALTER TABLE payment_holds ADD COLUMN IF NOT EXISTS otp_hash VARCHAR(255);
+ALTER TABLE payment_holds ADD COLUMN IF NOT EXISTS otp_attempts SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE payment_holds ADD COLUMN IF NOT EXISTS capture_on_confirm BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_merchant_id_created_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS description;
+ALTER TABLE transactions DROP COLUMN IF EXISTS merchant_id;
@@ -0,0 +1,3 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS merchant_id INTEGER REFERENCES merchants(id) ON DELETE SET NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS description VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_transactions_merchant_id_created_at ON transactions(merchant_id, created_at);
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS standing_orders (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    from_account_id INTEGER NOT NULL REFERENCES accounts(id),
+    to_account_id INTEGER NOT NULL REFERENCES accounts(id),
+    amount NUMERIC(12,2) NOT NULL CHECK(amount > 0),
+    description VARCHAR(255),
+    -- daily, weekly, monthly или cron-выражение
+    recurrence VARCHAR(100) NOT NULL,
+    start_at TIMESTAMP NOT NULL,
+    end_at TIMESTAMP,
+    on_insufficient_funds VARCHAR(10) NOT NULL DEFAULT 'skip' CHECK(on_insufficient_funds IN ('skip', 'retry')),
+    status VARCHAR(10) NOT NULL DEFAULT 'active' CHECK(status IN ('active', 'cancelled', 'completed')),
+    -- next_run_at — очередной плановый запуск, retry_at — повтор неудавшегося запуска
+    next_run_at TIMESTAMP,
+    retry_at TIMESTAMP,
+    attempts SMALLINT NOT NULL DEFAULT 0,
+    last_run_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    CHECK(from_account_id <> to_account_id)
+    );
+CREATE INDEX IF NOT EXISTS idx_standing_orders_user_id ON standing_orders(user_id);
+CREATE INDEX IF NOT EXISTS idx_standing_orders_status_next_run_at ON standing_orders(status, next_run_at);
+
+CREATE TABLE IF NOT EXISTS standing_order_executions (
+    id SERIAL PRIMARY KEY,
+    standing_order_id INTEGER NOT NULL REFERENCES standing_orders(id),
+    scheduled_at TIMESTAMP NOT NULL,
+    attempt SMALLINT NOT NULL,
+    amount NUMERIC(12,2) NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK(status IN ('succeeded', 'retrying', 'skipped', 'failed')),
+    error VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+CREATE INDEX IF NOT EXISTS idx_standing_order_executions_order_id ON standing_order_executions(standing_order_id, created_at);
@@ -0,0 +1,2 @@
+-- Аккаунт, на который зачисляются переводы по username или email пользователя
+ALTER TABLE users ADD COLUMN IF NOT EXISTS default_account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS payment_templates;
+DROP TABLE IF EXISTS beneficiaries;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS beneficiaries (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    nickname VARCHAR(100) NOT NULL,
+    kind VARCHAR(10) NOT NULL CHECK(kind IN ('account', 'card')),
+    account_id INTEGER REFERENCES accounts(id),
+    -- Номер карты не хранится: только ссылка на карту и маскированный номер
+    card_id INTEGER REFERENCES cards(id),
+    card_masked VARCHAR(19),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    CHECK((kind = 'account' AND account_id IS NOT NULL AND card_id IS NULL)
+        OR (kind = 'card' AND card_id IS NOT NULL AND account_id IS NULL))
+    );
+CREATE UNIQUE INDEX IF NOT EXISTS idx_beneficiaries_user_id_nickname ON beneficiaries(user_id, nickname) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS payment_templates (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    name VARCHAR(100) NOT NULL,
+    beneficiary_id INTEGER NOT NULL REFERENCES beneficiaries(id),
+    from_account_id INTEGER NOT NULL REFERENCES accounts(id),
+    amount NUMERIC(12,2) NOT NULL CHECK(amount > 0),
+    comment VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+    );
+CREATE INDEX IF NOT EXISTS idx_payment_templates_user_id ON payment_templates(user_id);
@@ -0,0 +1,5 @@
+-- Журнал только дополняется, поэтому ON DELETE SET NULL из 000005 не может обнулить actor_id:
+-- триггер append-only запрещает UPDATE. Пользователя с записями в журнале удалить нельзя.
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_actor_id_fkey;
+ALTER TABLE audit_logs
+    ADD CONSTRAINT audit_logs_actor_id_fkey FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE NO ACTION;